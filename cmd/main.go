@@ -5,11 +5,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/doufum/gitlab-analyze/internal/config"
 	"github.com/doufum/gitlab-analyze/pkg/gitlab"
 	"github.com/doufum/gitlab-analyze/pkg/excel"
+	"github.com/doufum/gitlab-analyze/pkg/export"
+	"github.com/doufum/gitlab-analyze/pkg/period"
+	"github.com/doufum/gitlab-analyze/pkg/report"
+	"github.com/doufum/gitlab-analyze/pkg/team"
+	"github.com/doufum/gitlab-analyze/schemas"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +27,57 @@ var (
 	startDate   string
 	endDate     string
 	projectFile string
+	periodExpr  string
+	fiscalStart string
+	compareTo   string
+	teamFile    string
+
+	// 作者排除规则
+	excludeAuthors []string
+	excludeEmails  []string
+	builtinBots    bool
+	checkBotFlag   bool
+
+	// 异常提交策略
+	outlierPolicy gitlab.OutlierPolicy
+	outlierAllow  []string
+
+	// 贡献统计方式
+	contributionMode string
+
+	// 自定义提交分类规则
+	commitCategories []string
+
+	// 任务编号规则
+	ticketPatterns []string
+
+	// 代码量归属方式
+	attribution string
+
+	// 是否统计合并请求和代码评审
+	mergeRequests bool
+	reviews       bool
+
+	// 是否检查直接推送到默认分支或受保护分支的提交
+	directPushes bool
+
+	// 是否统计 CI 流水线
+	pipelines bool
+
+	// DORA 指标
+	dora            bool
+	doraEnvironment string
+	incidentLabel   string
+
+	// 是否统计议题
+	issues bool
+
+	// 导出格式和选项
+	formats       []string
+	exportOptions export.Options
+
+	// 时间分桶粒度
+	bucket string
 )
 
 // 初始化环境变量
@@ -29,11 +87,15 @@ func init() {
 	}
 }
 
+// version 工具版本，构建时通过 -ldflags "-X main.version=<版本>" 注入
+var version = "dev"
+
 // 主命令
 var rootCmd = &cobra.Command{
-	Use:   "gitlab-analyze",
-	Short: "GitLab 项目成员代码贡献统计工具",
-	Long:  `一个用于分析 GitLab 项目成员代码贡献的命令行工具，支持多项目统计和数据导出。`,
+	Use:     "gitlab-analyze",
+	Version: version,
+	Short:   "GitLab 项目成员代码贡献统计工具",
+	Long:    `一个用于分析 GitLab 项目成员代码贡献的命令行工具，支持多项目统计和数据导出。`,
 }
 
 // analyze 子命令
@@ -44,15 +106,13 @@ var analyzeCmd = &cobra.Command{
 		// 记录开始时间
 		startTime := time.Now()
 
-		// 验证日期格式
-		if _, err := time.Parse("2006-01-02", startDate); err != nil {
-			fmt.Printf("错误: 开始日期格式无效，请使用 YYYY-MM-DD 格式\n")
-			os.Exit(1)
-		}
-		if _, err := time.Parse("2006-01-02", endDate); err != nil {
-			fmt.Printf("错误: 结束日期格式无效，请使用 YYYY-MM-DD 格式\n")
+		// 解析统计时间范围
+		statsRange, err := resolveRange(cmd)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
 			os.Exit(1)
 		}
+		startDate, endDate = statsRange.StartDate(), statsRange.EndDate()
 
 		// 读取项目信息
 		fmt.Printf("正在从 %s 读取项目信息...\n", projectFile)
@@ -64,7 +124,7 @@ var analyzeCmd = &cobra.Command{
 		fmt.Printf("成功读取 %d 个项目的信息\n", len(projectsInfo))
 
 		// 创建项目信息映射
-		projectInfoMap := make(map[string]excel.ProjectInfo)
+		projectInfoMap := make(map[string]gitlab.ProjectInfo)
 		for _, info := range projectsInfo {
 			projectInfoMap[info.ID] = info
 		}
@@ -72,31 +132,134 @@ var analyzeCmd = &cobra.Command{
 		// 创建 GitLab 客户端
 		client := gitlab.NewGitLabClient()
 
+		// 创建作者过滤器
+		authorFilter, err := gitlab.NewAuthorFilter(excludeAuthors, excludeEmails, builtinBots)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		if checkBotFlag {
+			authorFilter.CheckBotFlag(client)
+		}
+
+		// 校验异常提交策略
+		if err := gitlab.ValidateOutlierAction(outlierPolicy.Action); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		outlierPolicy.Allow = outlierAllow
+
+		// 校验贡献统计方式
+		if err := gitlab.ValidateContributionMode(contributionMode); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 校验代码量归属方式
+		if err := gitlab.ValidateAttribution(attribution); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 校验导出格式
+		if err := export.Validate(formats); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		if err := export.ValidateFileTemplate(exportOptions.FileTemplate, formats); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		if err := export.ValidateMarkdownSections(exportOptions.MarkdownSections); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		for _, format := range formats {
+			if format == "pdf" {
+				exportOptions.PDFFont, err = export.ResolvePDFFont(exportOptions.PDFFont)
+				if err != nil {
+					fmt.Printf("错误: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		// 校验时间分桶粒度
+		if err := gitlab.ValidateBucket(bucket); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 创建提交分类器
+		classifier, err := gitlab.NewClassifier(commitCategories)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 创建任务编号提取器
+		ticketExtractor, err := gitlab.NewTicketExtractor(ticketPatterns)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
 		// 获取项目 ID 列表
 		projectIDs := strings.Split(projects, ",")
-		var projectsStats []map[string]gitlab.UserStats
+		for i := range projectIDs {
+			projectIDs[i] = strings.TrimSpace(projectIDs[i])
+		}
+
+		// 解析对比时间范围
+		var compareRange period.Range
+		if compareTo != "" {
+			month, err := period.ParseMonth(fiscalStart)
+			if err != nil {
+				fmt.Printf("错误: 财年起始月份无效: %v\n", err)
+				os.Exit(1)
+			}
+			compareRange, err = period.ResolveComparison(compareTo, statsRange, time.Now(), month)
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
 		// 显示统计范围信息
 		fmt.Printf("\n统计范围:\n")
-		fmt.Printf("时间段: %s 至 %s\n", startDate, endDate)
+		fmt.Printf("时间段: %s\n", statsRange)
+		if compareTo != "" {
+			fmt.Printf("对比时间段: %s\n", compareRange)
+		}
 		fmt.Printf("项目数量: %d\n\n", len(projectIDs))
 
-		// 遍历每个项目获取统计信息
-		for i, projectID := range projectIDs {
-			projectID = strings.TrimSpace(projectID)
-			if info, exists := projectInfoMap[projectID]; exists {
-				fmt.Printf("[%d/%d] 正在分析项目: %s (%s) [ID: %s]\n", i+1, len(projectIDs), info.Name, info.PathWithNamespace, projectID)
-			} else {
-				fmt.Printf("[%d/%d] 正在分析项目 ID: %s (项目信息未找到)\n", i+1, len(projectIDs), projectID)
-			}
+		// 遍历每个项目获取提交
+		commits, failures := collectProjectCommits(client, projectIDs, projectInfoMap, startDate, endDate, attribution, report.StageCommits)
+		// 团队成员按全部提交的作者解析，包括之后被排除或作为异常处理的提交
+		collectedCommits := commits
 
-			// 获取项目统计信息
-			stats, err := client.GetProjectCommitStats(projectID, startDate, endDate)
-			if err != nil {
-				fmt.Printf("警告: 获取项目 %s 统计信息失败: %v\n", projectID, err)
-				continue
+		// 在排除作者之前识别回滚提交，原提交的作者被排除时仍能完成匹配
+		reverts := gitlab.DetectReverts(commits)
+
+		// 排除机器人和服务账号的提交
+		commits, excluded := authorFilter.Filter(commits)
+		if len(excluded) > 0 {
+			fmt.Printf("\n已按排除规则排除 %d 个作者的提交:\n", len(excluded))
+			for _, author := range excluded {
+				fmt.Printf("  %s: %d 个提交，%d 行代码 (%s)\n", author.Author, author.Commits, author.Total, author.Reason)
 			}
-			projectsStats = append(projectsStats, stats)
+		}
+
+		// 按贡献统计方式处理回滚提交
+		commits = gitlab.ApplyContributionMode(commits, reverts, contributionMode)
+		if len(reverts) > 0 {
+			fmt.Printf("\n识别到 %d 个回滚提交，贡献统计方式: %s\n", len(reverts), contributionMode)
+		}
+
+		// 检测并处理异常提交
+		commits, outliers := outlierPolicy.Apply(commits)
+		if len(outliers) > 0 {
+			fmt.Printf("\n检测到 %d 个异常提交，处理方式: %s\n", len(outliers), outlierPolicy.Action)
 		}
 
 		// 从环境变量获取目标用户列表
@@ -108,16 +271,225 @@ var analyzeCmd = &cobra.Command{
 			}
 		}
 
+		// 加载团队映射，目标用户中的团队或部门名称展开为成员
+		var teamMapping *team.Mapping
+		if teamFile != "" {
+			fmt.Printf("正在从 %s 读取团队映射...\n", teamFile)
+			teamMapping, err = team.Load(teamFile)
+			if err != nil {
+				fmt.Printf("错误: 读取团队映射失败: %v\n", err)
+				os.Exit(1)
+			}
+			targetUsers = teamMapping.ExpandUsers(targetUsers, statsRange.Start, statsRange.End.AddDate(0, 0, 1), collectedCommits)
+		}
+
 		// 合并所有项目的统计结果
 		fmt.Printf("\n正在合并统计结果...\n")
 		if len(targetUsers) > 0 {
 			fmt.Printf("将只统计以下用户: %s\n", strings.Join(targetUsers, ", "))
 		}
-		mergedStats := gitlab.MergeProjectStats(projectsStats, targetUsers)
+		targetCommits := gitlab.FilterCommitsByAuthors(commits, targetUsers)
+		mergedStats := gitlab.MergeProjectStats([]map[string]gitlab.UserStats{gitlab.CommitsToUserStats(targetCommits)}, targetUsers)
+		gitlab.AddRevertedStats(mergedStats, reverts, targetUsers, excluded)
+		outliers = filterOutliersByAuthors(outliers, targetUsers)
+		// DORA 指标按项目统计，需要保留全部回滚提交
+		allReverts := reverts
+		reverts = filterRevertsByAuthors(reverts, targetUsers)
+
+		rep := &report.Report{
+			Meta: report.RunMetadata{
+				Period:      statsRange.Name,
+				StartDate:   startDate,
+				EndDate:     endDate,
+				ProjectIDs:  projectIDs,
+				TargetUsers: targetUsers,
+				TeamFile:    teamFile,
+				GeneratedAt: startTime,
+				GitLabURL:   os.Getenv("GITLAB_URL"),
+				ToolVersion: version,
+
+				ContributionMode: contributionMode,
+				Attribution:      attribution,
+				CommitCategories: commitCategories,
+				TicketPatterns:   ticketPatterns,
+				Bucket:           bucket,
+			},
+			Stats:    mergedStats,
+			Projects: projectsInfo,
+			Commits:  targetCommits,
+			Excluded: excluded,
+			Outliers: outliers,
+			Reverts:  reverts,
+			Failures: failures,
+
+			Categories: classifier.Breakdown(targetCommits),
+		}
+		tickets := ticketExtractor.Analyze(targetCommits)
+		rep.Tickets = &tickets
+		rep.Buckets, err = gitlab.BucketCommits(targetCommits, bucket, startDate, endDate)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		if outlierPolicy.Enabled() {
+			rep.Meta.OutlierPolicy = describeOutlierPolicy(outlierPolicy)
+		}
+		for _, rule := range authorFilter.Rules {
+			if !rule.Builtin {
+				rep.Meta.ExclusionRules = append(rep.Meta.ExclusionRules, rule.String())
+			}
+		}
+		if builtinBots {
+			rep.Meta.ExclusionRules = append(rep.Meta.ExclusionRules, "内置机器人规则")
+		}
+		if checkBotFlag {
+			rep.Meta.ExclusionRules = append(rep.Meta.ExclusionRules, "GitLab bot 标记")
+		}
+
+		// 统计合并请求和代码评审
+		if mergeRequests || reviews {
+			fmt.Printf("\n正在获取合并请求...\n")
+			allMRs := collectProjectMergeRequests(client, rep, projectIDs, startDate, endDate)
+			mrs := authorFilter.FilterMergeRequests(allMRs)
+			mrs = gitlab.FilterMergeRequestsByAuthors(mrs, targetUsers)
+
+			if mergeRequests {
+				mrStats, err := gitlab.SummarizeMergeRequests(mrs, startDate, endDate)
+				if err != nil {
+					fmt.Printf("错误: %v\n", err)
+					os.Exit(1)
+				}
+				rep.MergeRequests = mrs
+				rep.MergeRequestStats = &mrStats
+				fmt.Printf("共 %d 个合并请求\n", len(mrs))
+			}
+
+			// 机器人创建的合并请求同样需要评审，评审统计使用全部合并请求，只按评审人过滤
+			if reviews {
+				include := func(user gitlab.User) bool {
+					if authorFilter.ExcludesUser(user) {
+						return false
+					}
+					return len(targetUsers) == 0 || containsUser(targetUsers, user.DisplayName()) || containsUser(targetUsers, user.Username)
+				}
+				ownProjects := gitlab.ContributedProjects(commits, allMRs)
+				reviewStats, err := gitlab.SummarizeReviews(allMRs, startDate, endDate, include, ownProjects)
+				if err != nil {
+					fmt.Printf("错误: %v\n", err)
+					os.Exit(1)
+				}
+				rep.Reviews = &reviewStats
+				fmt.Printf("共 %d 位评审人\n", len(reviewStats.Users))
+			}
+		}
+
+		// 检查直接推送到默认分支或受保护分支的提交
+		if directPushes {
+			fmt.Printf("\n正在检查直接推送的提交...\n")
+			var pushes []gitlab.DirectPush
+			for _, projectID := range projectIDs {
+				projectPushes, err := client.GetProjectDirectPushes(projectID, startDate, endDate)
+				if err != nil {
+					fmt.Printf("警告: 检查项目 %s 直接推送失败: %v\n", projectID, err)
+					rep.AddFailure(projectID, report.StageDirectPushes, err)
+					continue
+				}
+				pushes = append(pushes, projectPushes...)
+			}
+			rep.DirectPushes = append([]gitlab.DirectPush{}, gitlab.FilterDirectPushesByAuthors(pushes, targetUsers)...)
+			fmt.Printf("共 %d 个直接推送的提交\n", len(rep.DirectPushes))
+		}
+
+		// 统计 CI 流水线
+		if pipelines {
+			fmt.Printf("\n正在获取流水线...\n")
+			var allPipelines []gitlab.Pipeline
+			for _, projectID := range projectIDs {
+				projectPipelines, err := client.GetProjectPipelines(projectID, startDate, endDate)
+				if err != nil {
+					fmt.Printf("警告: 获取项目 %s 流水线失败: %v\n", projectID, err)
+					rep.AddFailure(projectID, report.StagePipelines, err)
+					continue
+				}
+				allPipelines = append(allPipelines, projectPipelines...)
+			}
+			pipelineStats := gitlab.SummarizePipelines(allPipelines, targetUsers)
+			rep.Pipelines = &pipelineStats
+			fmt.Printf("共 %d 条流水线\n", len(allPipelines))
+		}
+
+		// 计算 DORA 指标
+		if dora {
+			fmt.Printf("\n正在获取 %s 环境的部署和事故...\n", doraEnvironment)
+			events := make(map[string]gitlab.DORAEvents)
+			for _, projectID := range projectIDs {
+				projectEvents, err := client.GetProjectDORAEvents(projectID, startDate, endDate, doraEnvironment, incidentLabel)
+				if err != nil {
+					fmt.Printf("警告: 获取项目 %s 部署信息失败: %v\n", projectID, err)
+					rep.AddFailure(projectID, report.StageDeployments, err)
+					continue
+				}
+				events[projectID] = projectEvents
+			}
+			doraReport, err := gitlab.AnalyzeDORA(events, allReverts, doraEnvironment, startDate, endDate)
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				os.Exit(1)
+			}
+			rep.DORA = &doraReport
+			total := doraReport.Summary[""]
+			fmt.Printf("共 %d 次部署，%d 次失败，%d 个事故\n", total.Deployments, total.FailedDeployments, total.Incidents)
+		}
+
+		// 统计议题
+		if issues {
+			fmt.Printf("\n正在获取议题...\n")
+			var allIssues []gitlab.Issue
+			for _, projectID := range projectIDs {
+				projectIssues, err := client.GetProjectIssues(projectID, startDate, endDate)
+				if err != nil {
+					fmt.Printf("警告: 获取项目 %s 议题失败: %v\n", projectID, err)
+					rep.AddFailure(projectID, report.StageIssues, err)
+					continue
+				}
+				allIssues = append(allIssues, projectIssues...)
+			}
+			issueStats, err := gitlab.SummarizeIssues(allIssues, startDate, endDate, targetUsers)
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				os.Exit(1)
+			}
+			rep.Issues = &issueStats
+			fmt.Printf("共 %d 个议题\n", len(allIssues))
+		}
+
+		// 团队和部门汇总
+		if teamMapping != nil {
+			rep.Teams = teamMapping.Rollup(targetCommits, reverts)
+			fmt.Printf("团队汇总: %d 个团队，%d 个部门，%d 个未映射作者\n", len(rep.Teams.Teams), len(rep.Teams.Departments), len(rep.Teams.Unmapped))
+		}
+
+		// 统计对比时间段，重叠部分的提交详情会复用客户端缓存
+		if compareTo != "" {
+			fmt.Printf("\n正在统计对比时间段: %s\n", compareRange)
+			baselineCommits, baselineFailures := collectProjectCommits(client, projectIDs, projectInfoMap, compareRange.StartDate(), compareRange.EndDate(), attribution, report.StageCompareCommits)
+			rep.Failures = append(rep.Failures, baselineFailures...)
+			baselineReverts := gitlab.DetectReverts(baselineCommits)
+			baselineCommits, _ = authorFilter.Filter(baselineCommits)
+			baselineCommits = gitlab.ApplyContributionMode(baselineCommits, baselineReverts, contributionMode)
+			baselineCommits, _ = outlierPolicy.Apply(baselineCommits)
+			baselineStats := gitlab.MergeProjectStats([]map[string]gitlab.UserStats{gitlab.CommitsToUserStats(baselineCommits)}, targetUsers)
+			comparison := gitlab.CompareStats(mergedStats, baselineStats)
+			rep.Comparison = &comparison
+			rep.Meta.CompareTo = compareTo
+			rep.Meta.CompareStartDate = compareRange.StartDate()
+			rep.Meta.CompareEndDate = compareRange.EndDate()
+		}
 
 		// 导出统计结果
 		fmt.Printf("正在导出统计结果...\n")
-		if err := excel.ExportStatsToCSV(mergedStats, startDate, endDate, projectsInfo); err != nil {
+		rep.Meta.FinishedAt = time.Now()
+		if err := export.Run(rep, formats, exportOptions); err != nil {
 			fmt.Printf("错误: 导出统计结果失败: %v\n", err)
 			os.Exit(1)
 		}
@@ -125,7 +497,7 @@ var analyzeCmd = &cobra.Command{
 		// 计算并打印总耗时
 		elapsed := time.Since(startTime)
 		fmt.Printf("\n统计分析完成！总耗时: %s\n", elapsed)
-		fmt.Printf("统计结果已保存到 output 目录\n")
+		fmt.Printf("统计结果已保存到 %s 目录\n", exportOptions.OutputDir)
 	},
 }
 
@@ -180,20 +552,246 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// schema 子命令
+var schemaCmd = &cobra.Command{
+	Use:   "schema [名称]",
+	Short: "输出 JSON 和 NDJSON 导出格式的 JSON Schema",
+	Long:  fmt.Sprintf("输出内嵌的 JSON Schema，不指定名称时列出全部 Schema。可选名称: %s", strings.Join(schemas.Names(), "、")),
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			for _, name := range schemas.Names() {
+				fmt.Println(name)
+			}
+			return
+		}
+		data, err := schemas.Get(args[0])
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(data)
+	},
+}
+
 func init() {
+	cfg := config.LoadConfig()
+
 	// 添加子命令
 	rootCmd.AddCommand(analyzeCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(schemaCmd)
 
 	// 设置 analyze 命令的参数
 	analyzeCmd.Flags().StringVarP(&projects, "projects", "p", os.Getenv("DEFAULT_PROJECTS"), "要分析的项目 ID 列表，用逗号分隔")
-	analyzeCmd.Flags().StringVarP(&startDate, "start-date", "s", os.Getenv("DEFAULT_START_DATE"), "统计开始日期 (YYYY-MM-DD)")
-	analyzeCmd.Flags().StringVarP(&endDate, "end-date", "e", os.Getenv("DEFAULT_END_DATE"), "统计结束日期 (YYYY-MM-DD)")
-	analyzeCmd.Flags().StringVarP(&projectFile, "file", "f", os.Getenv("DEFAULT_PROJECT_FILE"), "项目信息 Excel 文件路径")
+	analyzeCmd.Flags().StringVarP(&startDate, "start-date", "s", cfg.DefaultStartDate, "统计开始日期 (YYYY-MM-DD)")
+	analyzeCmd.Flags().StringVarP(&endDate, "end-date", "e", cfg.DefaultEndDate, "统计结束日期 (YYYY-MM-DD)")
+	analyzeCmd.Flags().StringVarP(&projectFile, "file", "f", cfg.DefaultFile, "项目信息 Excel 文件路径")
+	analyzeCmd.Flags().StringVar(&periodExpr, "period", cfg.DefaultPeriod, "统计周期，如 last-7d、last-week、this-month、last-month、2026-Q3、2026-H1、FY2026，设置后覆盖开始和结束日期")
+	analyzeCmd.Flags().StringVar(&compareTo, "compare-to", "", "对比时间段: previous、previous-year、<start>:<end> 或周期表达式")
+	analyzeCmd.Flags().StringVar(&teamFile, "teams", cfg.TeamFile, "团队映射文件路径 (YAML 或 Excel)，用于团队和部门汇总")
+	analyzeCmd.Flags().StringSliceVar(&excludeAuthors, "exclude-author", splitList(cfg.ExcludeAuthors), "按作者名称排除提交的正则表达式，可重复指定")
+	analyzeCmd.Flags().StringSliceVar(&excludeEmails, "exclude-email", splitList(cfg.ExcludeEmails), "按作者邮箱排除提交的正则表达式，可重复指定")
+	analyzeCmd.Flags().BoolVar(&builtinBots, "builtin-bots", cfg.ExcludeBuiltinBots, "使用内置规则排除常见机器人账号 (renovate、dependabot、GitLab 等)")
+	analyzeCmd.Flags().BoolVar(&checkBotFlag, "check-bot-flag", cfg.CheckBotFlag, "通过 GitLab 用户接口检查作者的 bot 标记并排除")
+	analyzeCmd.Flags().StringVar(&outlierPolicy.Action, "outlier-policy", cfg.OutlierPolicy, "异常提交处理方式: off、flag (仅标记)、cap (截断到阈值)、exclude (排除)")
+	analyzeCmd.Flags().IntVar(&outlierPolicy.MaxLines, "outlier-max-lines", atoiOrDefault(cfg.OutlierMaxLines, 5000), "单个提交的绝对行数阈值 (增加+删除)，0 表示不启用")
+	analyzeCmd.Flags().Float64Var(&outlierPolicy.Percentile, "outlier-percentile", atofOrDefault(cfg.OutlierPercentile, 99), "统计规则使用的项目内提交行数百分位")
+	analyzeCmd.Flags().Float64Var(&outlierPolicy.Factor, "outlier-factor", atofOrDefault(cfg.OutlierFactor, 3), "统计规则的倍数 k，超过百分位行数 × k 即为异常，0 表示不启用")
+	analyzeCmd.Flags().IntVar(&outlierPolicy.MinSamples, "outlier-min-samples", atoiOrDefault(cfg.OutlierMinSamples, 20), "项目提交数少于该值时不使用统计规则")
+	analyzeCmd.Flags().StringSliceVar(&outlierAllow, "outlier-allow", splitList(cfg.OutlierAllow), "人工确认无需处理的异常提交 SHA (支持前缀)，可重复指定")
+	analyzeCmd.Flags().StringVar(&contributionMode, "contribution", cfg.ContributionMode, "贡献统计方式: gross (回滚和被回滚的提交都计入) 或 net (两者都不计入)")
+	analyzeCmd.Flags().StringArrayVar(&commitCategories, "category", splitCategories(cfg.CommitCategories), "自定义提交分类规则，格式为 名称=正则表达式，可重复指定")
+	analyzeCmd.Flags().StringArrayVar(&ticketPatterns, "ticket-pattern", splitCategories(cfg.TicketPatterns), "任务编号正则表达式，可重复指定，默认识别 #123、group/project#123 和 PAY-456")
+	analyzeCmd.Flags().BoolVar(&mergeRequests, "merge-requests", cfg.MergeRequests, "统计合并请求的数量、评审和合并时长、代码量和版本数")
+	analyzeCmd.Flags().BoolVar(&reviews, "reviews", cfg.Reviews, "统计代码评审参与情况：评审数、评论数、批准数、跨项目评审和评审人-作者矩阵")
+	analyzeCmd.Flags().StringVar(&attribution, "attribution", cfg.Attribution, "代码量归属方式: all (所有分支的提交) 或 merged-mr (只统计合并到默认分支或受保护分支的合并请求)")
+	analyzeCmd.Flags().BoolVar(&directPushes, "direct-pushes", cfg.DirectPushes, "检查没有经过合并请求直接推送到默认分支或受保护分支的提交")
+	analyzeCmd.Flags().BoolVar(&pipelines, "pipelines", cfg.Pipelines, "统计 CI 流水线的数量、成功率、运行时长和触发用户的失败次数")
+	analyzeCmd.Flags().BoolVar(&dora, "dora", cfg.DORA, "计算 DORA 指标：部署频率、变更前置时间、变更失败率和恢复时长")
+	analyzeCmd.Flags().StringVar(&doraEnvironment, "dora-environment", cfg.DORAEnvironment, "DORA 指标统计的部署环境")
+	analyzeCmd.Flags().StringVar(&incidentLabel, "incident-label", cfg.IncidentLabel, "事故议题的标签，类型为 incident 的议题同样视为事故")
+	analyzeCmd.Flags().BoolVar(&issues, "issues", cfg.Issues, "统计议题的创建和关闭数量、周期时长和工时，按负责人、标签和里程碑汇总")
+	fileTemplate := cfg.FileTemplate
+	if fileTemplate == "" {
+		fileTemplate = export.DefaultFileTemplate
+	}
+	analyzeCmd.Flags().StringSliceVar(&formats, "format", splitList(cfg.Formats), fmt.Sprintf("导出格式，可重复指定，可选值为 %s", strings.Join(export.Names(), "、")))
+	analyzeCmd.Flags().StringVar(&exportOptions.OutputDir, "output-dir", cfg.OutputDir, "输出目录")
+	analyzeCmd.Flags().StringVar(&exportOptions.FileTemplate, "file-template", fileTemplate, "不含扩展名的文件名模板，支持 {name} {user} {start} {end} {timestamp}")
+	analyzeCmd.Flags().BoolVar(&exportOptions.SplitByUser, "split-by-user", cfg.SplitByUser, "除合并统计文件外，额外为每个用户导出单独的 CSV 文件")
+	analyzeCmd.Flags().IntVar(&exportOptions.ChartTopN, "chart-top", atoiOrDefault(cfg.ChartTopN, 10), "XLSX 和 HTML 图表中展示的用户和项目数量，为 0 时 XLSX 不生成图表")
+	markdownSections := splitList(cfg.MarkdownSections)
+	if len(markdownSections) == 0 {
+		markdownSections = export.DefaultMarkdownSections
+	}
+	analyzeCmd.Flags().StringSliceVar(&exportOptions.MarkdownSections, "markdown-section", markdownSections, "Markdown 报告的章节及顺序，可重复指定: summary、contributors、projects、comparison、trend、matrix、tables、metadata")
+	analyzeCmd.Flags().StringVar(&exportOptions.SQLitePath, "sqlite-path", cfg.SQLitePath, "SQLite 数据库文件路径，为空时使用输出目录下的 gitlab_stats.db")
+	analyzeCmd.Flags().BoolVar(&exportOptions.SQLiteCommits, "sqlite-commits", cfg.SQLiteCommits, "在 SQLite 数据库中写入提交明细")
+	analyzeCmd.Flags().StringVar(&exportOptions.MetricsPath, "metrics-path", cfg.MetricsPath, "OpenMetrics 指标文件路径，为空时使用输出目录下的 gitlab_analyze.prom")
+	analyzeCmd.Flags().StringVar(&exportOptions.PDFFont, "pdf-font", cfg.PDFFont, "PDF 报告使用的支持中文的 TrueType (.ttf) 字体文件，为空时查找常见的系统字体")
+	analyzeCmd.Flags().StringVar(&bucket, "bucket", cfg.Bucket, "按时间区间分桶统计代码量: week 或 month，为空表示不分桶")
+	analyzeCmd.Flags().StringVar(&fiscalStart, "fiscal-start-month", cfg.FiscalYearStartMonth, "财年起始月份 (1-12)")
 
 	// 所有参数都有默认值，不需要标记为必需
 }
 
+// collectProjectCommits 遍历项目获取指定时间范围内的提交
+// attribution 为 merged-mr 时只统计合并到默认分支或受保护分支的合并请求中的提交
+func collectProjectCommits(client *gitlab.GitLabClient, projectIDs []string, projectInfoMap map[string]gitlab.ProjectInfo, startDate, endDate, attribution, stage string) ([]gitlab.Commit, []report.Failure) {
+	var commits []gitlab.Commit
+	var failures []report.Failure
+	for i, projectID := range projectIDs {
+		if info, exists := projectInfoMap[projectID]; exists {
+			fmt.Printf("[%d/%d] 正在分析项目: %s (%s) [ID: %s]\n", i+1, len(projectIDs), info.Name, info.PathWithNamespace, projectID)
+		} else {
+			fmt.Printf("[%d/%d] 正在分析项目 ID: %s (项目信息未找到)\n", i+1, len(projectIDs), projectID)
+		}
+
+		// 获取项目提交
+		getCommits := client.GetProjectCommits
+		if attribution == gitlab.AttributionMergedMR {
+			getCommits = client.GetProjectMergedCommits
+		}
+		projectCommits, err := getCommits(projectID, startDate, endDate)
+		if err != nil {
+			fmt.Printf("警告: 获取项目 %s 统计信息失败: %v\n", projectID, err)
+			failures = append(failures, report.Failure{ProjectID: projectID, Stage: stage, Error: err.Error()})
+			continue
+		}
+		commits = append(commits, projectCommits...)
+	}
+	return commits, failures
+}
+
+// containsUser 判断用户是否在列表中
+func containsUser(users []string, user string) bool {
+	for _, u := range users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+// collectProjectMergeRequests 遍历项目获取指定时间范围内的合并请求
+func collectProjectMergeRequests(client *gitlab.GitLabClient, rep *report.Report, projectIDs []string, startDate, endDate string) []gitlab.MergeRequest {
+	var mrs []gitlab.MergeRequest
+	for _, projectID := range projectIDs {
+		projectMRs, err := client.GetProjectMergeRequests(projectID, startDate, endDate)
+		if err != nil {
+			fmt.Printf("警告: 获取项目 %s 合并请求失败: %v\n", projectID, err)
+			rep.AddFailure(projectID, report.StageMergeRequests, err)
+			continue
+		}
+		mrs = append(mrs, projectMRs...)
+	}
+	return mrs
+}
+
+// resolveRange 根据 --period 或开始、结束日期确定统计时间范围
+func resolveRange(cmd *cobra.Command) (period.Range, error) {
+	datesChanged := cmd.Flags().Changed("start-date") || cmd.Flags().Changed("end-date")
+	if cmd.Flags().Changed("period") && datesChanged {
+		return period.Range{}, fmt.Errorf("--period 不能与 --start-date/--end-date 同时使用")
+	}
+
+	// 命令行显式指定的日期优先于 DEFAULT_PERIOD
+	if periodExpr != "" && !datesChanged {
+		month, err := period.ParseMonth(fiscalStart)
+		if err != nil {
+			return period.Range{}, fmt.Errorf("财年起始月份无效: %v", err)
+		}
+		return period.Resolve(periodExpr, time.Now(), month)
+	}
+
+	return period.NewRange(startDate, endDate)
+}
+
+// filterOutliersByAuthors 只保留目标用户的异常提交
+func filterOutliersByAuthors(outliers []gitlab.OutlierCommit, targetUsers []string) []gitlab.OutlierCommit {
+	if len(targetUsers) == 0 {
+		return outliers
+	}
+	var filtered []gitlab.OutlierCommit
+	for _, outlier := range outliers {
+		for _, user := range targetUsers {
+			if outlier.AuthorName == user {
+				filtered = append(filtered, outlier)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// filterRevertsByAuthors 只保留回滚作者或原提交作者为目标用户的回滚记录
+func filterRevertsByAuthors(reverts []gitlab.RevertPair, targetUsers []string) []gitlab.RevertPair {
+	if len(targetUsers) == 0 {
+		return reverts
+	}
+	var filtered []gitlab.RevertPair
+	for _, pair := range reverts {
+		for _, user := range targetUsers {
+			if pair.Revert.AuthorName == user || (pair.Original != nil && pair.Original.AuthorName == user) {
+				filtered = append(filtered, pair)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// describeOutlierPolicy 返回异常提交策略的描述，写入运行元数据
+func describeOutlierPolicy(p gitlab.OutlierPolicy) string {
+	var rules []string
+	if p.MaxLines > 0 {
+		rules = append(rules, fmt.Sprintf("绝对阈值 %d 行", p.MaxLines))
+	}
+	if p.Factor > 0 {
+		rules = append(rules, fmt.Sprintf("P%g × %g (至少 %d 个提交)", p.Percentile, p.Factor, p.MinSamples))
+	}
+	return fmt.Sprintf("%s: %s", p.Action, strings.Join(rules, "，"))
+}
+
+// atoiOrDefault 将配置项转换为整数，无效时返回默认值
+func atoiOrDefault(value string, defaultValue int) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return n
+	}
+	return defaultValue
+}
+
+// atofOrDefault 将配置项转换为浮点数，无效时返回默认值
+func atofOrDefault(value string, defaultValue float64) float64 {
+	if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		return f
+	}
+	return defaultValue
+}
+
+// splitList 拆分逗号分隔的配置项，忽略空白项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitCategories 拆分分号分隔的提交分类或任务编号规则
+func splitCategories(value string) []string {
+	var specs []string
+	for _, spec := range strings.Split(value, ";") {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
 // truncateString 截断过长的字符串并添加省略号
 func truncateString(s string, maxLen int) string {
 	runeStr := []rune(s)
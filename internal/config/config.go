@@ -16,9 +16,82 @@ type Config struct {
 	DefaultStartDate string
 	DefaultEndDate   string
 	DefaultFile      string
+	DefaultPeriod    string
+
+	// 财年起始月份（1-12）
+	FiscalYearStartMonth string
 
 	// 目标用户
 	TargetUsers string
+
+	// 团队映射文件
+	TeamFile string
+
+	// 作者排除规则
+	ExcludeAuthors     string
+	ExcludeEmails      string
+	ExcludeBuiltinBots bool
+	CheckBotFlag       bool
+
+	// 异常提交策略
+	OutlierPolicy     string
+	OutlierMaxLines   string
+	OutlierPercentile string
+	OutlierFactor     string
+	OutlierMinSamples string
+	OutlierAllow      string
+
+	// 贡献统计方式：gross 或 net
+	ContributionMode string
+
+	// 自定义提交分类规则，格式为 名称=正则表达式，多个规则用分号分隔
+	CommitCategories string
+
+	// 任务编号规则，多个规则用分号分隔，为空时使用默认规则
+	TicketPatterns string
+
+	// 代码量归属方式：all 或 merged-mr
+	Attribution string
+
+	// 是否统计合并请求和代码评审
+	MergeRequests bool
+	Reviews       bool
+
+	// 是否检查直接推送的提交
+	DirectPushes bool
+
+	// 是否统计 CI 流水线
+	Pipelines bool
+
+	// DORA 指标
+	DORA            bool
+	DORAEnvironment string
+	IncidentLabel   string
+
+	// 是否统计议题
+	Issues bool
+
+	// 导出格式（逗号分隔）、输出目录和文件名模板
+	Formats      string
+	OutputDir    string
+	FileTemplate string
+
+	// 是否额外为每个用户导出单独的 CSV 文件
+	SplitByUser bool
+	// XLSX 图表中展示的用户和项目数量
+	ChartTopN string
+	// Markdown 报告的章节（逗号分隔）
+	MarkdownSections string
+	// SQLite 数据库文件路径，以及是否写入提交明细
+	SQLitePath    string
+	SQLiteCommits bool
+	// OpenMetrics 指标文件路径
+	MetricsPath string
+	// PDF 报告使用的 TrueType 字体文件路径
+	PDFFont string
+
+	// 时间分桶粒度：week 或 month
+	Bucket string
 }
 
 // LoadConfig 加载配置
@@ -39,7 +112,48 @@ func LoadConfig() *Config {
 		DefaultStartDate: getEnvOrDefault("DEFAULT_START_DATE", defaultStartDate),
 		DefaultEndDate:   getEnvOrDefault("DEFAULT_END_DATE", defaultEndDate),
 		DefaultFile:      getEnvOrDefault("DEFAULT_PROJECT_FILE", "projects.xlsx"),
-		TargetUsers:      os.Getenv("TARGET_USERS"),
+		DefaultPeriod:    os.Getenv("DEFAULT_PERIOD"),
+
+		FiscalYearStartMonth: getEnvOrDefault("FISCAL_YEAR_START_MONTH", "1"),
+
+		TargetUsers: os.Getenv("TARGET_USERS"),
+		TeamFile:    os.Getenv("TEAM_FILE"),
+
+		ExcludeAuthors:     os.Getenv("EXCLUDE_AUTHORS"),
+		ExcludeEmails:      os.Getenv("EXCLUDE_EMAILS"),
+		ExcludeBuiltinBots: getEnvOrDefault("EXCLUDE_BUILTIN_BOTS", "true") == "true",
+		CheckBotFlag:       os.Getenv("CHECK_BOT_FLAG") == "true",
+
+		OutlierPolicy:     getEnvOrDefault("OUTLIER_POLICY", "flag"),
+		OutlierMaxLines:   getEnvOrDefault("OUTLIER_MAX_LINES", "5000"),
+		OutlierPercentile: getEnvOrDefault("OUTLIER_PERCENTILE", "99"),
+		OutlierFactor:     getEnvOrDefault("OUTLIER_FACTOR", "3"),
+		OutlierMinSamples: getEnvOrDefault("OUTLIER_MIN_SAMPLES", "20"),
+		OutlierAllow:      os.Getenv("OUTLIER_ALLOW"),
+
+		ContributionMode: getEnvOrDefault("CONTRIBUTION_MODE", "gross"),
+		CommitCategories: os.Getenv("COMMIT_CATEGORIES"),
+		TicketPatterns:   os.Getenv("TICKET_PATTERNS"),
+		Attribution:      getEnvOrDefault("ATTRIBUTION", "all"),
+		MergeRequests:    os.Getenv("MERGE_REQUESTS") == "true",
+		Reviews:          os.Getenv("REVIEWS") == "true",
+		DirectPushes:     os.Getenv("DIRECT_PUSHES") == "true",
+		Pipelines:        os.Getenv("PIPELINES") == "true",
+		DORA:             os.Getenv("DORA") == "true",
+		DORAEnvironment:  getEnvOrDefault("DORA_ENVIRONMENT", "production"),
+		IncidentLabel:    getEnvOrDefault("INCIDENT_LABEL", "incident"),
+		Issues:           os.Getenv("ISSUES") == "true",
+		Formats:          getEnvOrDefault("FORMATS", "csv"),
+		OutputDir:        getEnvOrDefault("OUTPUT_DIR", "output"),
+		FileTemplate:     os.Getenv("FILE_TEMPLATE"),
+		SplitByUser:      os.Getenv("SPLIT_BY_USER") == "true",
+		ChartTopN:        getEnvOrDefault("CHART_TOP_N", "10"),
+		MarkdownSections: os.Getenv("MARKDOWN_SECTIONS"),
+		SQLitePath:       os.Getenv("SQLITE_PATH"),
+		SQLiteCommits:    os.Getenv("SQLITE_COMMITS") == "true",
+		MetricsPath:      os.Getenv("METRICS_PATH"),
+		PDFFont:          os.Getenv("PDF_FONT"),
+		Bucket:           os.Getenv("BUCKET"),
 	}
 }
 
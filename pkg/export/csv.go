@@ -0,0 +1,111 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/doufum/gitlab-analyze/pkg/report"
+)
+
+func init() {
+	Register("csv", csvExporter{})
+}
+
+// csvExporter 每个结果表导出一个 CSV 文件
+type csvExporter struct{}
+
+func (csvExporter) multiFile() bool { return true }
+
+// Export 导出统计结果到 CSV 文件
+func (csvExporter) Export(rep *report.Report, opts Options) error {
+	// 记录已写入的文件，不同的表生成相同的文件名时报错而不是互相覆盖
+	written := make(map[string]string)
+	write := func(table report.Table, path string) error {
+		if previous, ok := written[path]; ok {
+			return fmt.Errorf("%s 和 %s 的输出文件名相同: %s", previous, table.Name, path)
+		}
+		written[path] = table.Name
+		return writeTableToCSV(table, path)
+	}
+
+	// 写入运行元数据、合并统计和附加结果表，每个表一个文件
+	tables := append([]report.Table{rep.Meta.Table(), rep.ConsolidatedTable()}, rep.Tables()...)
+	for _, table := range tables {
+		if err := write(table, opts.Path(rep, table.Name, "", "csv")); err != nil {
+			return err
+		}
+	}
+
+	if !opts.SplitByUser {
+		return nil
+	}
+
+	// 为每个用户创建独立的统计文件，{name} 加上 user_ 前缀，避免与结果表重名
+	for _, user := range rep.SortedUsers() {
+		stat := rep.Stats[user]
+		table := report.Table{
+			Name:   "user_" + user,
+			Header: []string{"用户名", "项目名称", "项目路径", "增加行数", "删除行数", "变更行数", "总代码量", "被回滚行数"},
+		}
+		// 写入用户在每个项目中的统计数据
+		for projectID, projectStat := range stat.Projects {
+			project := rep.Project(projectID)
+			table.Rows = append(table.Rows, []interface{}{
+				user,
+				project.Name,
+				project.PathWithNamespace,
+				projectStat.Additions,
+				projectStat.Deletions,
+				projectStat.Changes,
+				projectStat.Additions + projectStat.Deletions,
+				projectStat.Reverted,
+			})
+		}
+
+		if err := write(table, opts.Path(rep, table.Name, user, "csv")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTableToCSV 将结果表写入 CSV 文件
+func writeTableToCSV(table report.Table, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("创建 CSV 文件失败: %v", err)
+	}
+	defer file.Close()
+
+	// 写入 UTF-8 BOM
+	file.Write([]byte{0xEF, 0xBB, 0xBF})
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(table.Header); err != nil {
+		return fmt.Errorf("写入表头失败: %v", err)
+	}
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = formatCell(cell)
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("写入数据失败: %v", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatCell 将单元格转换为文本
+func formatCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,198 @@
+package export
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/doufum/gitlab-analyze/pkg/report"
+)
+
+// DefaultFileTemplate 默认的文件名模板
+const DefaultFileTemplate = "gitlab_stats_{name}_{start}_{end}_{timestamp}"
+
+// Options 导出选项，各导出格式只使用与自己相关的字段
+type Options struct {
+	// OutputDir 输出目录
+	OutputDir string
+	// FileTemplate 不含扩展名的文件名模板，支持以下占位符：
+	// {name} 结果表名称或用户名，{user} 用户名（仅按用户导出的文件），
+	// {start}、{end} 统计开始和结束日期，{timestamp} 生成时间
+	FileTemplate string
+
+	// SplitByUser 是否额外为每个用户导出单独的 CSV 文件
+	SplitByUser bool
+	// ChartTopN 图表中展示的用户和项目数量，为 0 时 XLSX 不生成图表、HTML 使用默认数量
+	ChartTopN int
+	// MarkdownSections Markdown 报告的章节及顺序，为空时使用默认章节
+	MarkdownSections []string
+	// SQLitePath SQLite 数据库文件路径，为空时使用输出目录下的 gitlab_stats.db
+	SQLitePath string
+	// SQLiteCommits 是否在 SQLite 数据库中写入提交明细
+	SQLiteCommits bool
+	// MetricsPath OpenMetrics 指标文件路径，为空时使用输出目录下的 gitlab_analyze.prom
+	MetricsPath string
+	// PDFFont PDF 报告使用的支持中文的 TrueType 字体文件路径，为空时查找常见的系统字体
+	PDFFont string
+}
+
+// Exporter 统计结果的导出格式
+type Exporter interface {
+	Export(rep *report.Report, opts Options) error
+}
+
+// multiFileExporter 由每次导出多个文件的导出格式实现，这类格式的文件名模板必须包含 {name}
+type multiFileExporter interface {
+	multiFile() bool
+}
+
+// exporters 已注册的导出格式
+var exporters = make(map[string]Exporter)
+
+// Register 注册导出格式，名称重复时覆盖
+func Register(name string, exporter Exporter) {
+	exporters[name] = exporter
+}
+
+// Get 根据名称获取导出格式
+func Get(name string) (Exporter, error) {
+	exporter, ok := exporters[name]
+	if !ok {
+		return nil, fmt.Errorf("不支持的导出格式: %s，可选值为 %s", name, strings.Join(Names(), "、"))
+	}
+	return exporter, nil
+}
+
+// Names 返回已注册的导出格式名称，按字母排序
+func Names() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate 校验导出格式名称
+func Validate(formats []string) error {
+	if len(formats) == 0 {
+		return fmt.Errorf("至少需要指定一种导出格式，可选值为 %s", strings.Join(Names(), "、"))
+	}
+	for _, format := range formats {
+		if _, err := Get(format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateFileTemplate 校验文件名模板，导出多个文件的格式要求模板包含 {name}，否则文件会互相覆盖
+func ValidateFileTemplate(template string, formats []string) error {
+	if template == "" || strings.Contains(template, "{name}") {
+		return nil
+	}
+	for _, format := range formats {
+		if exporter, ok := exporters[format].(multiFileExporter); ok && exporter.multiFile() {
+			return fmt.Errorf("文件名模板 %s 不包含 {name}，%s 格式导出的多个文件会互相覆盖", template, format)
+		}
+	}
+	return nil
+}
+
+// Run 依次使用指定的导出格式导出统计结果，重复的格式只导出一次
+func Run(rep *report.Report, formats []string, opts Options) error {
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %v", err)
+	}
+	done := make(map[string]bool)
+	for _, format := range formats {
+		if done[format] {
+			continue
+		}
+		done[format] = true
+		exporter, err := Get(format)
+		if err != nil {
+			return err
+		}
+		if err := exporter.Export(rep, opts); err != nil {
+			return fmt.Errorf("导出 %s 失败: %v", format, err)
+		}
+	}
+	return nil
+}
+
+// repeatedSeparators 占位符为空时留下的连续分隔符
+var repeatedSeparators = regexp.MustCompile(`[_-]{2,}`)
+
+// Path 根据文件名模板生成输出文件路径，name 为结果表名称或用户名，user 仅在按用户导出时不为空，
+// ext 为不含点的扩展名。占位符为空时，其两侧多余的分隔符会被合并
+func (o Options) Path(rep *report.Report, name, user, ext string) string {
+	template := o.FileTemplate
+	if template == "" {
+		template = DefaultFileTemplate
+	}
+	fileName := strings.NewReplacer(
+		"{name}", SanitizeFileName(name),
+		"{user}", SanitizeFileName(user),
+		"{start}", rep.Meta.StartDate,
+		"{end}", rep.Meta.EndDate,
+		"{timestamp}", rep.Meta.Timestamp(),
+	).Replace(template)
+	fileName = repeatedSeparators.ReplaceAllStringFunc(fileName, func(s string) string { return s[:1] })
+	fileName = strings.Trim(fileName, "_-")
+	if fileName == "" {
+		fileName = "gitlab_stats"
+	}
+	return filepath.Join(o.OutputDir, fileName+"."+ext)
+}
+
+// numericColumns 返回每一列是否为数字列：所有非空单元格都是数字且至少有一个数字
+func numericColumns(table report.Table) []bool {
+	numeric := make([]bool, len(table.Header))
+	for i := range numeric {
+		numbers, others := 0, 0
+		for _, row := range table.Rows {
+			if i >= len(row) {
+				continue
+			}
+			switch row[i].(type) {
+			case int, float64:
+				numbers++
+			default:
+				if row[i] != "" {
+					others++
+				}
+			}
+		}
+		numeric[i] = numbers > 0 && others == 0
+	}
+	return numeric
+}
+
+// SanitizeFileName 将文件名中的路径分隔符、空白和其他不能用于文件名的字符替换为下划线。
+// 名称被修改时追加原名称哈希值的前 6 位，避免 li/si 和 li_si 这样的不同名称生成相同的文件名
+func SanitizeFileName(name string) string {
+	if name == "" {
+		return ""
+	}
+	sanitized := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if sanitized == "." || sanitized == ".." {
+		sanitized = "_"
+	}
+	if sanitized != name {
+		sum := sha1.Sum([]byte(name))
+		sanitized += "_" + hex.EncodeToString(sum[:])[:6]
+	}
+	return sanitized
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+
+	"github.com/doufum/gitlab-analyze/pkg/report"
+)
+
+//go:embed templates/report.html
+var htmlTemplate string
+
+func init() {
+	Register("html", htmlExporter{})
+}
+
+// htmlExporter 导出一个离线可用的 HTML 报告，样式和脚本全部内嵌
+type htmlExporter struct{}
+
+// htmlTable 附加结果表在页面中的数据
+type htmlTable struct {
+	Title  string          `json:"title"`
+	Header []string        `json:"header"`
+	Rows   [][]interface{} `json:"rows"`
+}
+
+// htmlData 页面脚本使用的数据
+type htmlData struct {
+	Report jsonReport `json:"report"`
+	// ChartTopN 图表中展示的用户数量
+	ChartTopN int         `json:"chart_top_n"`
+	Metadata  [][2]string `json:"metadata"`
+	Tables    []htmlTable `json:"tables"`
+}
+
+// Export 导出统计结果到 HTML 文件
+func (htmlExporter) Export(rep *report.Report, opts Options) error {
+	tmpl, err := template.New("report").Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("解析 HTML 模板失败: %v", err)
+	}
+
+	data := htmlData{
+		Report:    newJSONReport(rep),
+		ChartTopN: opts.ChartTopN,
+		Metadata:  rep.Meta.Fields(),
+		Tables:    []htmlTable{},
+	}
+	for _, table := range rep.Tables() {
+		// 空表的 Rows 为 nil，序列化为 null 会导致页面脚本出错
+		rows := table.Rows
+		if rows == nil {
+			rows = [][]interface{}{}
+		}
+		data.Tables = append(data.Tables, htmlTable{Title: table.Title, Header: table.Header, Rows: rows})
+	}
+
+	file, err := os.Create(opts.Path(rep, "", "", "html"))
+	if err != nil {
+		return fmt.Errorf("创建 HTML 文件失败: %v", err)
+	}
+	defer file.Close()
+
+	title := fmt.Sprintf("GitLab 代码贡献报告 %s ~ %s", rep.Meta.StartDate, rep.Meta.EndDate)
+	if err := tmpl.Execute(file, map[string]interface{}{"Title": title, "Data": data}); err != nil {
+		return fmt.Errorf("写入 HTML 文件失败: %v", err)
+	}
+	return nil
+}
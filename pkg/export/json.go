@@ -0,0 +1,306 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+	"github.com/doufum/gitlab-analyze/pkg/report"
+)
+
+// SchemaVersion JSON 和 NDJSON 输出的结构版本，字段出现不兼容的变化时递增，
+// 对应 schemas 目录下的 JSON Schema 文件
+const SchemaVersion = "1"
+
+func init() {
+	Register("json", jsonExporter{})
+	Register("ndjson", ndjsonExporter{})
+}
+
+// jsonReport JSON 输出的顶层结构
+type jsonReport struct {
+	SchemaVersion string        `json:"schema_version"`
+	Run           jsonRun       `json:"run"`
+	Users         []jsonUser    `json:"users"`
+	Projects      []jsonProject `json:"projects"`
+	Buckets       []jsonBucket  `json:"buckets,omitempty"`
+	Failures      []jsonFailure `json:"failures"`
+}
+
+// jsonRun 运行元数据
+type jsonRun struct {
+	Tool            jsonTool       `json:"tool"`
+	InstanceURL     string         `json:"instance_url"`
+	StartedAt       time.Time      `json:"started_at"`
+	FinishedAt      time.Time      `json:"finished_at"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	Parameters      jsonParameters `json:"parameters"`
+}
+
+type jsonTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// jsonParameters 运行参数
+type jsonParameters struct {
+	Period           string   `json:"period"`
+	StartDate        string   `json:"start_date"`
+	EndDate          string   `json:"end_date"`
+	ProjectIDs       []string `json:"project_ids"`
+	TargetUsers      []string `json:"target_users"`
+	TeamFile         string   `json:"team_file"`
+	ExclusionRules   []string `json:"exclusion_rules"`
+	OutlierPolicy    string   `json:"outlier_policy"`
+	ContributionMode string   `json:"contribution_mode"`
+	Attribution      string   `json:"attribution"`
+	CommitCategories []string `json:"commit_categories"`
+	TicketPatterns   []string `json:"ticket_patterns"`
+	Bucket           string   `json:"bucket"`
+	CompareTo        string   `json:"compare_to"`
+	CompareStartDate string   `json:"compare_start_date"`
+	CompareEndDate   string   `json:"compare_end_date"`
+}
+
+// jsonStats 代码量统计
+type jsonStats struct {
+	Commits   int `json:"commits"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+	Changes   int `json:"changes"`
+	Total     int `json:"total"`
+	Reverted  int `json:"reverted"`
+}
+
+type jsonUser struct {
+	User string `json:"user"`
+	jsonStats
+	Projects []jsonUserProject `json:"projects"`
+}
+
+type jsonUserProject struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	ProjectPath string `json:"project_path"`
+	jsonStats
+}
+
+type jsonProject struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	ProjectPath string `json:"project_path"`
+	jsonStats
+	Contributors int `json:"contributors"`
+}
+
+// jsonBucket 时间区间的统计，区间内的被回滚行数不单独统计
+type jsonBucket struct {
+	Label string     `json:"label"`
+	Start string     `json:"start"`
+	Users []jsonUser `json:"users"`
+}
+
+type jsonFailure struct {
+	ProjectID string `json:"project_id"`
+	Stage     string `json:"stage"`
+	Error     string `json:"error"`
+}
+
+// jsonRecord NDJSON 的一行，对应一个用户在一个项目中的统计
+type jsonRecord struct {
+	SchemaVersion string    `json:"schema_version"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	StartDate     string    `json:"start_date"`
+	EndDate       string    `json:"end_date"`
+	User          string    `json:"user"`
+	ProjectID     string    `json:"project_id"`
+	ProjectName   string    `json:"project_name"`
+	ProjectPath   string    `json:"project_path"`
+	jsonStats
+}
+
+// jsonExporter 导出包含完整结果模型的 JSON 文件
+type jsonExporter struct{}
+
+// Export 导出统计结果到 JSON 文件
+func (jsonExporter) Export(rep *report.Report, opts Options) error {
+	file, err := os.Create(opts.Path(rep, "", "", "json"))
+	if err != nil {
+		return fmt.Errorf("创建 JSON 文件失败: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newJSONReport(rep)); err != nil {
+		return fmt.Errorf("写入 JSON 文件失败: %v", err)
+	}
+	return nil
+}
+
+// ndjsonExporter 每行导出一个用户在一个项目中的统计
+type ndjsonExporter struct{}
+
+// Export 导出统计结果到 NDJSON 文件
+func (ndjsonExporter) Export(rep *report.Report, opts Options) error {
+	file, err := os.Create(opts.Path(rep, "", "", "ndjson"))
+	if err != nil {
+		return fmt.Errorf("创建 NDJSON 文件失败: %v", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	for _, user := range rep.SortedUsers() {
+		for _, project := range userProjects(rep, rep.Stats[user]) {
+			record := jsonRecord{
+				SchemaVersion: SchemaVersion,
+				GeneratedAt:   rep.Meta.GeneratedAt,
+				StartDate:     rep.Meta.StartDate,
+				EndDate:       rep.Meta.EndDate,
+				User:          user,
+				ProjectID:     project.ProjectID,
+				ProjectName:   project.ProjectName,
+				ProjectPath:   project.ProjectPath,
+				jsonStats:     project.jsonStats,
+			}
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("写入 NDJSON 文件失败: %v", err)
+			}
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("写入 NDJSON 文件失败: %v", err)
+	}
+	return nil
+}
+
+// newJSONReport 将统计结果转换为 JSON 输出结构
+func newJSONReport(rep *report.Report) jsonReport {
+	meta := rep.Meta
+	out := jsonReport{
+		SchemaVersion: SchemaVersion,
+		Run: jsonRun{
+			Tool:            jsonTool{Name: "gitlab-analyze", Version: meta.ToolVersion},
+			InstanceURL:     meta.GitLabURL,
+			StartedAt:       meta.GeneratedAt,
+			FinishedAt:      meta.FinishedAt,
+			DurationSeconds: meta.Duration().Seconds(),
+			Parameters: jsonParameters{
+				Period:           meta.Period,
+				StartDate:        meta.StartDate,
+				EndDate:          meta.EndDate,
+				ProjectIDs:       nonNil(meta.ProjectIDs),
+				TargetUsers:      nonNil(meta.TargetUsers),
+				TeamFile:         meta.TeamFile,
+				ExclusionRules:   nonNil(meta.ExclusionRules),
+				OutlierPolicy:    meta.OutlierPolicy,
+				ContributionMode: meta.ContributionMode,
+				Attribution:      meta.Attribution,
+				CommitCategories: nonNil(meta.CommitCategories),
+				TicketPatterns:   nonNil(meta.TicketPatterns),
+				Bucket:           meta.Bucket,
+				CompareTo:        meta.CompareTo,
+				CompareStartDate: meta.CompareStartDate,
+				CompareEndDate:   meta.CompareEndDate,
+			},
+		},
+		Users:    jsonUsers(rep, rep.Stats, rep.SortedUsers()),
+		Projects: []jsonProject{},
+		Failures: []jsonFailure{},
+	}
+
+	for _, total := range rep.ProjectTotals() {
+		out.Projects = append(out.Projects, jsonProject{
+			ProjectID:    total.ID,
+			ProjectName:  total.Name,
+			ProjectPath:  total.PathWithNamespace,
+			jsonStats:    projectStats(total.ProjectStats),
+			Contributors: total.Contributors,
+		})
+	}
+	for _, bucket := range rep.Buckets {
+		users := make([]string, 0, len(bucket.Stats))
+		for user := range bucket.Stats {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+		out.Buckets = append(out.Buckets, jsonBucket{
+			Label: bucket.Label,
+			Start: bucket.Start.Format("2006-01-02"),
+			Users: jsonUsers(rep, bucket.Stats, users),
+		})
+	}
+	for _, failure := range rep.Failures {
+		out.Failures = append(out.Failures, jsonFailure{ProjectID: failure.ProjectID, Stage: failure.Stage, Error: failure.Error})
+	}
+	return out
+}
+
+// jsonUsers 按给定顺序转换用户统计
+func jsonUsers(rep *report.Report, stats map[string]gitlab.UserStats, users []string) []jsonUser {
+	result := make([]jsonUser, 0, len(users))
+	for _, user := range users {
+		stat := stats[user]
+		result = append(result, jsonUser{
+			User: user,
+			jsonStats: jsonStats{
+				Commits:   stat.Commits,
+				Additions: stat.Additions,
+				Deletions: stat.Deletions,
+				Changes:   stat.Changes,
+				Total:     stat.Total,
+				Reverted:  stat.Reverted,
+			},
+			Projects: userProjects(rep, stat),
+		})
+	}
+	return result
+}
+
+// userProjects 转换用户在各项目中的统计，按项目 ID 排序
+func userProjects(rep *report.Report, stat gitlab.UserStats) []jsonUserProject {
+	ids := make([]string, 0, len(stat.Projects))
+	for projectID := range stat.Projects {
+		ids = append(ids, projectID)
+	}
+	sort.Strings(ids)
+
+	projects := make([]jsonUserProject, 0, len(ids))
+	for _, projectID := range ids {
+		project := rep.Project(projectID)
+		projects = append(projects, jsonUserProject{
+			ProjectID:   projectID,
+			ProjectName: project.Name,
+			ProjectPath: project.PathWithNamespace,
+			jsonStats:   projectStats(stat.Projects[projectID]),
+		})
+	}
+	return projects
+}
+
+// projectStats 转换项目维度的代码量统计
+func projectStats(s gitlab.ProjectStats) jsonStats {
+	return jsonStats{
+		Commits:   s.Commits,
+		Additions: s.Additions,
+		Deletions: s.Deletions,
+		Changes:   s.Changes,
+		Total:     s.Additions + s.Deletions,
+		Reverted:  s.Reverted,
+	}
+}
+
+// nonNil 将 nil 切片转换为空切片，使 JSON 输出为 [] 而不是 null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
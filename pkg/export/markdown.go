@@ -0,0 +1,260 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+	"github.com/doufum/gitlab-analyze/pkg/report"
+)
+
+// Markdown 报告的章节
+const (
+	SectionSummary      = "summary"
+	SectionContributors = "contributors"
+	SectionProjects     = "projects"
+	SectionComparison   = "comparison"
+	SectionTrend        = "trend"
+	SectionMatrix       = "matrix"
+	SectionTables       = "tables"
+	SectionMetadata     = "metadata"
+)
+
+// DefaultMarkdownSections 默认输出的 Markdown 章节，按顺序排列
+var DefaultMarkdownSections = []string{
+	SectionSummary, SectionContributors, SectionProjects, SectionComparison, SectionTrend, SectionMetadata,
+}
+
+// markdownSections 全部可选的 Markdown 章节
+var markdownSections = []string{
+	SectionSummary, SectionContributors, SectionProjects, SectionComparison,
+	SectionTrend, SectionMatrix, SectionTables, SectionMetadata,
+}
+
+// 主要贡献者表默认展示的用户数量
+const defaultContributors = 10
+
+func init() {
+	Register("markdown", markdownExporter{})
+}
+
+// ValidateMarkdownSections 校验 Markdown 章节名称
+func ValidateMarkdownSections(sections []string) error {
+	for _, section := range sections {
+		valid := false
+		for _, name := range markdownSections {
+			if section == name {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("无效的 Markdown 章节: %s，可选值为 %s", section, strings.Join(markdownSections, "、"))
+		}
+	}
+	return nil
+}
+
+// markdownExporter 导出适用于 GitLab Wiki 和合并请求描述的 Markdown 报告
+type markdownExporter struct{}
+
+// Export 导出统计结果到 Markdown 文件
+func (markdownExporter) Export(rep *report.Report, opts Options) error {
+	file, err := os.Create(opts.Path(rep, "", "", "md"))
+	if err != nil {
+		return fmt.Errorf("创建 Markdown 文件失败: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	writeMarkdownReport(w, rep, opts)
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("写入 Markdown 文件失败: %v", err)
+	}
+	return nil
+}
+
+// writeMarkdownReport 按指定的章节顺序写入报告，没有数据的章节跳过
+func writeMarkdownReport(w *bufio.Writer, rep *report.Report, opts Options) {
+	sections := opts.MarkdownSections
+	if len(sections) == 0 {
+		sections = DefaultMarkdownSections
+	}
+
+	fmt.Fprintf(w, "# GitLab 代码贡献统计 %s ~ %s\n\n", rep.Meta.StartDate, rep.Meta.EndDate)
+	period := rep.Meta.Period
+	if period == "" {
+		period = "自定义"
+	}
+	fmt.Fprintf(w, "> 统计周期：%s，生成时间：%s\n", period, rep.Meta.GeneratedAt.Format("2006-01-02 15:04"))
+
+	for _, section := range sections {
+		switch section {
+		case SectionSummary:
+			writeMarkdownSection(w, "汇总", rep.SummaryTable())
+		case SectionContributors:
+			limit := opts.ChartTopN
+			if limit <= 0 {
+				limit = defaultContributors
+			}
+			table := rep.UserTable()
+			if len(table.Rows) > limit {
+				table.Rows = table.Rows[:limit]
+			}
+			table.Header = append([]string{"排名"}, table.Header...)
+			for i := range table.Rows {
+				table.Rows[i] = append([]interface{}{i + 1}, table.Rows[i]...)
+			}
+			writeMarkdownSection(w, fmt.Sprintf("主要贡献者（前 %d 名）", len(table.Rows)), table)
+		case SectionProjects:
+			writeMarkdownSection(w, "项目统计", rep.ProjectTable())
+		case SectionComparison:
+			if rep.Comparison != nil {
+				title := fmt.Sprintf("周期对比（%s ~ %s）", rep.Meta.CompareStartDate, rep.Meta.CompareEndDate)
+				writeMarkdownSection(w, title, comparisonUserTable(rep))
+				writeMarkdownTable(w, comparisonProjectTable(rep))
+			}
+		case SectionTrend:
+			if len(rep.Buckets) > 0 {
+				writeMarkdownSection(w, "总代码量趋势", rep.TrendTable(opts.ChartTopN))
+			}
+		case SectionMatrix:
+			writeMarkdownSection(w, "用户×项目", rep.MatrixTable())
+		case SectionTables:
+			tables := rep.Tables()
+			if len(tables) > 0 {
+				fmt.Fprintf(w, "\n## 附加统计\n")
+				for _, table := range tables {
+					writeMarkdownDetails(w, table.Title, table)
+				}
+			}
+		case SectionMetadata:
+			fmt.Fprintf(w, "\n## 运行元数据\n")
+			writeMarkdownDetails(w, "展开查看", rep.Meta.Table())
+		}
+	}
+}
+
+// writeMarkdownSection 写入带二级标题的表格
+func writeMarkdownSection(w *bufio.Writer, title string, table report.Table) {
+	fmt.Fprintf(w, "\n## %s\n", title)
+	writeMarkdownTable(w, table)
+}
+
+// writeMarkdownDetails 写入可折叠的表格，GitLab 支持在 details 中使用 Markdown
+func writeMarkdownDetails(w *bufio.Writer, summary string, table report.Table) {
+	fmt.Fprintf(w, "\n<details>\n<summary>%s</summary>\n", escapeMarkdown(summary))
+	writeMarkdownTable(w, table)
+	fmt.Fprintf(w, "\n</details>\n")
+}
+
+// writeMarkdownTable 写入 Markdown 表格，数字列右对齐
+func writeMarkdownTable(w *bufio.Writer, table report.Table) {
+	if len(table.Rows) == 0 {
+		fmt.Fprintf(w, "\n无数据\n")
+		return
+	}
+
+	numeric := numericColumns(table)
+	cells := make([]string, len(table.Header))
+	for i, name := range table.Header {
+		cells[i] = escapeMarkdown(name)
+	}
+	fmt.Fprintf(w, "\n| %s |\n", strings.Join(cells, " | "))
+	for i := range cells {
+		cells[i] = "---"
+		if numeric[i] {
+			cells[i] = "---:"
+		}
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	for _, row := range table.Rows {
+		cells := make([]string, len(table.Header))
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = escapeMarkdown(markdownCell(row[i]))
+			}
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// markdownCell 将单元格转换为文本，小数保留两位
+func markdownCell(cell interface{}) string {
+	if v, ok := cell.(float64); ok {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return fmt.Sprint(cell)
+}
+
+// markdownEscaper 转义表格中的竖线、强调标记、反斜杠和尖括号，换行替换为空格
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`",
+	"<", "&lt;", ">", "&gt;", "\r\n", " ", "\n", " ",
+)
+
+// escapeMarkdown 转义 Markdown 中有特殊含义的字符
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// comparisonUserTable 周期对比中每个用户的总代码量变化
+func comparisonUserTable(rep *report.Report) report.Table {
+	table := report.Table{Header: append([]string{"用户名", "状态"}, totalDeltaHeader()...)}
+	for _, user := range rep.Comparison.Users {
+		row := []interface{}{user.User, report.ContributorStatusName(user.Status)}
+		table.Rows = append(table.Rows, append(row, totalDeltaCells(user.Total)...))
+	}
+	return table
+}
+
+// comparisonProjectTable 周期对比中每个项目的总代码量变化
+func comparisonProjectTable(rep *report.Report) report.Table {
+	table := report.Table{Header: append([]string{"项目", "状态"}, totalDeltaHeader()...)}
+	for _, total := range rep.ProjectTotals() {
+		if delta, ok := rep.Comparison.Projects[total.ID]; ok {
+			table.Rows = append(table.Rows, append([]interface{}{projectName(total.ProjectInfo), report.ContributorStatusName(delta.Status)}, totalDeltaCells(delta.Total)...))
+		}
+	}
+	// 对比期有、当前期没有的项目排在最后
+	var disappeared []string
+	for projectID, delta := range rep.Comparison.Projects {
+		if delta.Status == gitlab.ContributorDisappeared {
+			disappeared = append(disappeared, projectID)
+		}
+	}
+	sort.Strings(disappeared)
+	for _, projectID := range disappeared {
+		delta := rep.Comparison.Projects[projectID]
+		table.Rows = append(table.Rows, append([]interface{}{projectName(rep.Project(projectID)), report.ContributorStatusName(delta.Status)}, totalDeltaCells(delta.Total)...))
+	}
+	return table
+}
+
+// totalDeltaHeader 总代码量变化列的表头
+func totalDeltaHeader() []string {
+	return []string{"当前总代码量", "对比总代码量", "差值", "变化率"}
+}
+
+// totalDeltaCells 总代码量变化列的单元格，差值和变化率带正负号
+func totalDeltaCells(m gitlab.MetricDelta) []interface{} {
+	change := "-"
+	if m.HasPercent {
+		change = fmt.Sprintf("%+.1f%%", m.Percent)
+	}
+	return []interface{}{m.Current, m.Previous, fmt.Sprintf("%+d", m.Delta), change}
+}
+
+// projectName 项目的展示名称，优先使用项目路径
+func projectName(project gitlab.ProjectInfo) string {
+	if project.PathWithNamespace != "" {
+		return project.PathWithNamespace
+	}
+	if project.Name != "" {
+		return project.Name
+	}
+	return project.ID
+}
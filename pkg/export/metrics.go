@@ -0,0 +1,142 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/doufum/gitlab-analyze/pkg/report"
+)
+
+// DefaultMetricsFile 未指定指标文件路径时在输出目录下使用的文件名，
+// node_exporter 的 textfile 采集器只读取 .prom 文件
+const DefaultMetricsFile = "gitlab_analyze.prom"
+
+func init() {
+	Register("openmetrics", metricsExporter{})
+}
+
+// metricsExporter 导出 OpenMetrics 文本格式的指标文件，供 node_exporter 的 textfile 采集器读取
+type metricsExporter struct{}
+
+// metric 一个指标及其全部样本
+type metric struct {
+	name    string
+	help    string
+	samples []metricSample
+}
+
+type metricSample struct {
+	labels [][2]string
+	value  float64
+}
+
+// Export 导出统计结果到指标文件。先写入同目录下的临时文件再重命名，避免采集器读到写了一半的文件
+func (metricsExporter) Export(rep *report.Report, opts Options) error {
+	path := opts.MetricsPath
+	if path == "" {
+		path = filepath.Join(opts.OutputDir, DefaultMetricsFile)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建指标文件目录失败: %v", err)
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建指标文件失败: %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	w := bufio.NewWriter(file)
+	writeMetrics(w, rep)
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("写入指标文件失败: %v", err)
+	}
+	// CreateTemp 创建的文件权限为 0600，改为采集器可读
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		return fmt.Errorf("设置指标文件权限失败: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入指标文件失败: %v", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("替换指标文件失败: %v", err)
+	}
+	return nil
+}
+
+// writeMetrics 写入全部指标，每个用户在每个项目中的统计为一个样本
+func writeMetrics(w *bufio.Writer, rep *report.Report) {
+	additions := metric{name: "gitlab_analyze_additions", help: "统计范围内用户在项目中增加的代码行数"}
+	deletions := metric{name: "gitlab_analyze_deletions", help: "统计范围内用户在项目中删除的代码行数"}
+	commits := metric{name: "gitlab_analyze_commits", help: "统计范围内用户在项目中的提交数"}
+	for _, user := range rep.SortedUsers() {
+		stat := rep.Stats[user]
+		for _, projectID := range report.SortedKeys(stat.Projects) {
+			s := stat.Projects[projectID]
+			labels := [][2]string{{"user", user}, {"project", projectName(rep.Project(projectID))}}
+			additions.samples = append(additions.samples, metricSample{labels, float64(s.Additions)})
+			deletions.samples = append(deletions.samples, metricSample{labels, float64(s.Deletions)})
+			commits.samples = append(commits.samples, metricSample{labels, float64(s.Commits)})
+		}
+	}
+
+	meta := rep.Meta
+	metrics := []metric{
+		additions,
+		deletions,
+		commits,
+		{
+			name: "gitlab_analyze_run_info",
+			help: "运行信息，值固定为 1",
+			samples: []metricSample{{labels: [][2]string{
+				{"version", meta.ToolVersion}, {"start_date", meta.StartDate}, {"end_date", meta.EndDate},
+			}, value: 1}},
+		},
+		{
+			name:    "gitlab_analyze_run_duration_seconds",
+			help:    "运行耗时（秒）",
+			samples: []metricSample{{value: meta.Duration().Seconds()}},
+		},
+		{
+			name:    "gitlab_analyze_run_timestamp_seconds",
+			help:    "运行完成时间的 Unix 时间戳",
+			samples: []metricSample{{value: float64(meta.FinishedAt.Unix())}},
+		},
+		{
+			name:    "gitlab_analyze_failures",
+			help:    "获取数据失败的项目阶段数",
+			samples: []metricSample{{value: float64(len(rep.Failures))}},
+		},
+	}
+
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", m.name)
+		for _, sample := range m.samples {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, metricLabels(sample.labels), strconv.FormatFloat(sample.value, 'f', -1, 64))
+		}
+	}
+	fmt.Fprintf(w, "# EOF\n")
+}
+
+// metricLabels 格式化标签，没有标签时返回空字符串
+func metricLabels(labels [][2]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		pairs[i] = fmt.Sprintf("%s=\"%s\"", label[0], metricLabelEscaper.Replace(label[1]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// metricLabelEscaper 转义标签值中的反斜杠、双引号和换行
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
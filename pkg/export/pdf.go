@@ -0,0 +1,383 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/doufum/gitlab-analyze/pkg/report"
+)
+
+// pdfFontCandidates 未指定字体时依次查找的系统中文字体，只支持 TrueType (.ttf) 字体。
+// fonts-noto-cjk、思源黑体等常见中文字体包只提供 .ttc/.otf，无法使用
+var pdfFontCandidates = []string{
+	"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf",
+	"/usr/share/fonts/google-droid-sans-fonts/DroidSansFallbackFull.ttf",
+	"/usr/share/fonts/TTF/DroidSansFallbackFull.ttf",
+	"/Library/Fonts/Arial Unicode.ttf",
+	"/System/Library/Fonts/Supplemental/Arial Unicode.ttf",
+	`C:\Windows\Fonts\simhei.ttf`,
+}
+
+// PDF 页面布局，单位为毫米
+const (
+	pdfMargin    = 15.0
+	pdfRowHeight = 6.0
+	pdfFontSize  = 9.0
+	// 条形图的标签宽度和每个条形的高度
+	pdfBarLabelWidth = 45.0
+	pdfBarHeight     = 5.0
+	// 表格中文本列的最大宽度
+	pdfMaxTextWidth = 60.0
+)
+
+// 主要贡献者条形图默认展示的用户数量
+const defaultPDFChartTopN = 10
+
+// pdfFontFamily 注册到文档中的字体名称
+const pdfFontFamily = "cjk"
+
+func init() {
+	Register("pdf", pdfExporter{})
+}
+
+// ResolvePDFFont 返回 PDF 报告使用的字体文件路径。未指定时查找常见的系统中文字体，找不到时返回错误
+func ResolvePDFFont(path string) (string, error) {
+	if path != "" {
+		if !strings.EqualFold(filepath.Ext(path), ".ttf") {
+			return "", fmt.Errorf("PDF 字体只支持 TrueType (.ttf) 字体文件: %s", path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("PDF 字体文件不可用: %v", err)
+		}
+		return path, nil
+	}
+	for _, candidate := range pdfFontCandidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("未找到支持中文的字体，请安装 Droid Sans Fallback 字体（Debian/Ubuntu: fonts-droid-fallback，" +
+		"Fedora: google-droid-sans-fonts，Arch: ttf-droid），或通过 --pdf-font 或 PDF_FONT 指定 TrueType (.ttf) 字体文件")
+}
+
+// pdfExporter 导出用于归档的 PDF 报告，包含封面、汇总表、条形图和各项目的统计
+type pdfExporter struct{}
+
+// Export 导出统计结果到 PDF 文件
+func (pdfExporter) Export(rep *report.Report, opts Options) error {
+	fontPath, err := ResolvePDFFont(opts.PDFFont)
+	if err != nil {
+		return err
+	}
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return fmt.Errorf("读取 PDF 字体失败: %v", err)
+	}
+
+	title := fmt.Sprintf("GitLab 代码贡献报告 %s ~ %s", rep.Meta.StartDate, rep.Meta.EndDate)
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", font)
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("gitlab-analyze "+rep.Meta.ToolVersion, true)
+	pdf.SetCreationDate(rep.Meta.GeneratedAt)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		// 封面不显示页脚
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(-pdfMargin + 5)
+		pdf.SetFont(pdfFontFamily, "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 5, title, "", 0, "L", false, 0, "")
+		pdf.SetX(pdfMargin)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	chartTopN := opts.ChartTopN
+	if chartTopN <= 0 {
+		chartTopN = defaultPDFChartTopN
+	}
+	p := &pdfWriter{Fpdf: pdf}
+	p.cover(rep)
+	p.summary(rep, chartTopN)
+	p.projects(rep, chartTopN)
+	for _, table := range rep.Tables() {
+		if table.Name == "failures" {
+			p.AddPage()
+			p.heading(table.Title, 0)
+			p.table(table)
+		}
+	}
+
+	if err := pdf.OutputFileAndClose(opts.Path(rep, "", "", "pdf")); err != nil {
+		return fmt.Errorf("写入 PDF 文件失败: %v", err)
+	}
+	return nil
+}
+
+// pdfWriter 在 fpdf 文档上绘制报告内容
+type pdfWriter struct {
+	*fpdf.Fpdf
+}
+
+// contentWidth 页面内容区域的宽度
+func (p *pdfWriter) contentWidth() float64 {
+	width, _ := p.GetPageSize()
+	left, _, right, _ := p.GetMargins()
+	return width - left - right
+}
+
+// ensureSpace 当前页剩余高度不足 height 时换页，返回是否换页
+func (p *pdfWriter) ensureSpace(height float64) bool {
+	_, pageHeight := p.GetPageSize()
+	_, _, _, bottom := p.GetMargins()
+	if p.GetY()+height > pageHeight-bottom {
+		p.AddPage()
+		return true
+	}
+	return false
+}
+
+// heading 写入标题并添加书签，level 为 0 时为一级标题
+func (p *pdfWriter) heading(text string, level int) {
+	size := 16.0
+	if level > 0 {
+		size = 12
+	}
+	p.ensureSpace(size + pdfRowHeight*3)
+	p.Bookmark(text, level, -1)
+	p.SetFont(pdfFontFamily, "", size)
+	p.SetTextColor(31, 78, 121)
+	p.CellFormat(0, size*0.6, text, "", 1, "L", false, 0, "")
+	p.SetTextColor(0, 0, 0)
+	p.Ln(2)
+}
+
+// cover 写入封面：统计周期、项目列表和运行参数
+func (p *pdfWriter) cover(rep *report.Report) {
+	p.AddPage()
+	p.Bookmark("封面", 0, -1)
+	p.Ln(30)
+	p.SetFont(pdfFontFamily, "", 22)
+	p.CellFormat(0, 12, "GitLab 代码贡献报告", "", 1, "C", false, 0, "")
+	p.SetFont(pdfFontFamily, "", 14)
+	p.CellFormat(0, 10, fmt.Sprintf("%s ~ %s", rep.Meta.StartDate, rep.Meta.EndDate), "", 1, "C", false, 0, "")
+	p.SetFont(pdfFontFamily, "", 10)
+	p.SetTextColor(100, 100, 100)
+	p.CellFormat(0, 8, "生成时间："+rep.Meta.GeneratedAt.Format("2006-01-02 15:04"), "", 1, "C", false, 0, "")
+	p.SetTextColor(0, 0, 0)
+	p.Ln(12)
+
+	projects := report.Table{Title: "统计项目", Header: []string{"项目 ID", "项目名称", "项目路径"}}
+	for _, project := range rep.Projects {
+		projects.Rows = append(projects.Rows, []interface{}{project.ID, project.Name, project.PathWithNamespace})
+	}
+	p.heading("统计项目", 1)
+	p.table(projects)
+	p.Ln(6)
+	p.heading("运行参数", 1)
+	p.table(rep.Meta.Table())
+}
+
+// summary 写入汇总表、用户和项目的条形图及统计表
+func (p *pdfWriter) summary(rep *report.Report, chartTopN int) {
+	p.AddPage()
+	p.heading("汇总", 0)
+	p.table(rep.SummaryTable())
+
+	users := rep.SortedUsers()
+	var userBars []pdfBar
+	for _, user := range users {
+		userBars = append(userBars, pdfBar{Label: user, Value: rep.Stats[user].Total})
+	}
+	p.Ln(6)
+	p.barChart(fmt.Sprintf("总代码量前 %d 名用户", min(chartTopN, len(userBars))), userBars, chartTopN)
+
+	var projectBars []pdfBar
+	for _, total := range rep.ProjectTotals() {
+		projectBars = append(projectBars, pdfBar{Label: projectName(total.ProjectInfo), Value: total.Additions + total.Deletions})
+	}
+	p.Ln(6)
+	p.barChart(fmt.Sprintf("总代码量前 %d 个项目", min(chartTopN, len(projectBars))), projectBars, chartTopN)
+
+	p.AddPage()
+	p.heading("用户统计", 0)
+	p.table(rep.UserTable())
+	p.Ln(6)
+	p.heading("项目统计", 0)
+	p.table(rep.ProjectTable())
+}
+
+// projects 为每个有提交的项目写入一节，包含项目合计、用户条形图和用户统计表
+func (p *pdfWriter) projects(rep *report.Report, chartTopN int) {
+	totals := rep.ProjectTotals()
+	if len(totals) == 0 {
+		return
+	}
+	p.AddPage()
+	p.heading("各项目统计", 0)
+	for i, total := range totals {
+		if i > 0 {
+			p.Ln(8)
+		}
+		p.heading(projectName(total.ProjectInfo), 1)
+		p.SetFont(pdfFontFamily, "", pdfFontSize)
+		p.CellFormat(0, pdfRowHeight, fmt.Sprintf("提交数 %d，增加 %d 行，删除 %d 行，总代码量 %d，贡献者 %d 人",
+			total.Commits, total.Additions, total.Deletions, total.Additions+total.Deletions, total.Contributors), "", 1, "L", false, 0, "")
+		p.Ln(2)
+
+		table := rep.ProjectUserTable(total.ID)
+		var bars []pdfBar
+		for _, row := range table.Rows {
+			user := fmt.Sprint(row[0])
+			s := rep.Stats[user].Projects[total.ID]
+			bars = append(bars, pdfBar{Label: user, Value: s.Additions + s.Deletions})
+		}
+		p.barChart("", bars, chartTopN)
+		p.Ln(2)
+		p.table(table)
+	}
+}
+
+// pdfBar 条形图中的一个条形
+type pdfBar struct {
+	Label string
+	Value int
+}
+
+// barChart 绘制水平条形图，最多展示 limit 个条形
+func (p *pdfWriter) barChart(title string, bars []pdfBar, limit int) {
+	if len(bars) > limit {
+		bars = bars[:limit]
+	}
+	if len(bars) == 0 {
+		return
+	}
+	maxValue := 0
+	for _, bar := range bars {
+		maxValue = max(maxValue, bar.Value)
+	}
+
+	height := float64(len(bars)) * (pdfBarHeight + 1)
+	if title != "" {
+		height += pdfRowHeight
+	}
+	p.ensureSpace(height)
+	p.SetFont(pdfFontFamily, "", pdfFontSize)
+	if title != "" {
+		p.CellFormat(0, pdfRowHeight, title, "", 1, "L", false, 0, "")
+	}
+
+	left, _, _, _ := p.GetMargins()
+	valueWidth := 20.0
+	barArea := p.contentWidth() - pdfBarLabelWidth - valueWidth
+	p.SetFillColor(91, 155, 213)
+	for _, bar := range bars {
+		y := p.GetY()
+		p.SetX(left)
+		p.CellFormat(pdfBarLabelWidth, pdfBarHeight, p.fit(bar.Label, pdfBarLabelWidth-2), "", 0, "R", false, 0, "")
+		width := 0.0
+		if maxValue > 0 {
+			width = barArea * float64(bar.Value) / float64(maxValue)
+		}
+		if width > 0 {
+			p.Rect(left+pdfBarLabelWidth+1, y+0.5, width, pdfBarHeight-1, "F")
+		}
+		p.SetXY(left+pdfBarLabelWidth+1+width+1, y)
+		p.CellFormat(valueWidth, pdfBarHeight, strconv.Itoa(bar.Value), "", 0, "L", false, 0, "")
+		p.SetXY(left, y+pdfBarHeight+1)
+	}
+}
+
+// table 绘制表格，列宽按内容分配，数字列右对齐，跨页时重复表头
+func (p *pdfWriter) table(table report.Table) {
+	p.SetFont(pdfFontFamily, "", pdfFontSize)
+	if len(table.Rows) == 0 {
+		p.CellFormat(0, pdfRowHeight, "无数据", "", 1, "L", false, 0, "")
+		return
+	}
+
+	numeric := numericColumns(table)
+	cells := make([][]string, len(table.Rows))
+	// 内容需要的宽度，以及限制文本列最大宽度后的列宽
+	natural := make([]float64, len(table.Header))
+	for i, name := range table.Header {
+		natural[i] = p.GetStringWidth(name) + 4
+	}
+	for r, row := range table.Rows {
+		cells[r] = make([]string, len(table.Header))
+		for i := range table.Header {
+			if i < len(row) {
+				cells[r][i] = markdownCell(row[i])
+				natural[i] = max(natural[i], p.GetStringWidth(cells[r][i])+4)
+			}
+		}
+	}
+	widths := make([]float64, len(natural))
+	total, capped := 0.0, 0.0
+	for i, width := range natural {
+		widths[i] = min(width, pdfMaxTextWidth)
+		total += widths[i]
+		capped += width - widths[i]
+	}
+	// 页面有剩余宽度时按比例分给被限制的列，总宽度超出页面时按比例缩小，文本过长的单元格会被截断
+	if spare := p.contentWidth() - total; spare > 0 && capped > 0 {
+		for i := range widths {
+			widths[i] += min(spare, capped) * (natural[i] - widths[i]) / capped
+		}
+	} else if spare < 0 {
+		scale := p.contentWidth() / total
+		for i := range widths {
+			widths[i] *= scale
+		}
+	}
+
+	header := func() {
+		p.SetFillColor(221, 235, 247)
+		for i, name := range table.Header {
+			align := "L"
+			if numeric[i] {
+				align = "R"
+			}
+			p.CellFormat(widths[i], pdfRowHeight, p.fit(name, widths[i]-2), "1", 0, align, true, 0, "")
+		}
+		p.Ln(-1)
+	}
+	p.ensureSpace(pdfRowHeight * 2)
+	header()
+	for _, row := range cells {
+		if p.ensureSpace(pdfRowHeight) {
+			p.SetFont(pdfFontFamily, "", pdfFontSize)
+			header()
+		}
+		for i, cell := range row {
+			align := "L"
+			if numeric[i] {
+				align = "R"
+			}
+			p.CellFormat(widths[i], pdfRowHeight, p.fit(cell, widths[i]-2), "1", 0, align, false, 0, "")
+		}
+		p.Ln(-1)
+	}
+}
+
+// fit 截断超出宽度的文本，末尾加省略号
+func (p *pdfWriter) fit(text string, width float64) string {
+	if p.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && p.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
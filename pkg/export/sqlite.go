@@ -0,0 +1,264 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+	"github.com/doufum/gitlab-analyze/pkg/report"
+
+	// 纯 Go 实现的 SQLite 驱动，不依赖 CGO
+	_ "modernc.org/sqlite"
+)
+
+// DefaultSQLiteFile 未指定数据库路径时在输出目录下使用的文件名
+const DefaultSQLiteFile = "gitlab_stats.db"
+
+// sqliteSchemaVersion 数据库结构版本，记录在 PRAGMA user_version 中
+const sqliteSchemaVersion = 1
+
+// sqliteSchema 建表语句，每次运行的数据都通过 run_id 关联到 runs 表
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS runs (
+		run_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tool_version TEXT NOT NULL,
+		instance_url TEXT NOT NULL,
+		started_at TEXT NOT NULL,
+		finished_at TEXT NOT NULL,
+		duration_seconds REAL NOT NULL,
+		period TEXT NOT NULL,
+		start_date TEXT NOT NULL,
+		end_date TEXT NOT NULL,
+		contribution_mode TEXT NOT NULL,
+		attribution TEXT NOT NULL,
+		bucket TEXT NOT NULL,
+		parameters TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS projects (
+		run_id INTEGER NOT NULL REFERENCES runs(run_id),
+		project_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		path TEXT NOT NULL,
+		PRIMARY KEY (run_id, project_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS users (
+		run_id INTEGER NOT NULL REFERENCES runs(run_id),
+		user TEXT NOT NULL,
+		commits INTEGER NOT NULL,
+		additions INTEGER NOT NULL,
+		deletions INTEGER NOT NULL,
+		changes INTEGER NOT NULL,
+		total INTEGER NOT NULL,
+		reverted INTEGER NOT NULL,
+		PRIMARY KEY (run_id, user)
+	)`,
+	`CREATE TABLE IF NOT EXISTS user_project_stats (
+		run_id INTEGER NOT NULL REFERENCES runs(run_id),
+		user TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		commits INTEGER NOT NULL,
+		additions INTEGER NOT NULL,
+		deletions INTEGER NOT NULL,
+		changes INTEGER NOT NULL,
+		total INTEGER NOT NULL,
+		reverted INTEGER NOT NULL,
+		PRIMARY KEY (run_id, user, project_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS buckets (
+		run_id INTEGER NOT NULL REFERENCES runs(run_id),
+		label TEXT NOT NULL,
+		start_date TEXT NOT NULL,
+		user TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		commits INTEGER NOT NULL,
+		additions INTEGER NOT NULL,
+		deletions INTEGER NOT NULL,
+		changes INTEGER NOT NULL,
+		total INTEGER NOT NULL,
+		PRIMARY KEY (run_id, label, user, project_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS commits (
+		run_id INTEGER NOT NULL REFERENCES runs(run_id),
+		project_id TEXT NOT NULL,
+		sha TEXT NOT NULL,
+		author_name TEXT NOT NULL,
+		author_email TEXT NOT NULL,
+		authored_at TEXT NOT NULL,
+		committed_at TEXT NOT NULL,
+		title TEXT NOT NULL,
+		additions INTEGER NOT NULL,
+		deletions INTEGER NOT NULL,
+		changes INTEGER NOT NULL,
+		PRIMARY KEY (run_id, project_id, sha)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_user_project_stats_user ON user_project_stats (user)`,
+	`CREATE INDEX IF NOT EXISTS idx_user_project_stats_project ON user_project_stats (project_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_commits_author ON commits (author_name)`,
+}
+
+func init() {
+	Register("sqlite", sqliteExporter{})
+}
+
+// sqliteExporter 将统计结果追加写入 SQLite 数据库，多次运行的结果保存在同一个数据库中，便于查询历史
+type sqliteExporter struct{}
+
+// Export 导出统计结果到 SQLite 数据库
+func (sqliteExporter) Export(rep *report.Report, opts Options) error {
+	path := opts.SQLitePath
+	if path == "" {
+		path = filepath.Join(opts.OutputDir, DefaultSQLiteFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建数据库目录失败: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("打开数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	if err := migrateSQLite(db); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	if err := insertSQLiteRun(tx, rep, opts.SQLiteCommits); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// migrateSQLite 创建数据库结构，数据库由更新版本的工具创建时返回错误
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("读取数据库结构版本失败: %v", err)
+	}
+	if version > sqliteSchemaVersion {
+		return fmt.Errorf("数据库结构版本 %d 高于当前支持的版本 %d", version, sqliteSchemaVersion)
+	}
+	for _, stmt := range sqliteSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("创建数据库表失败: %v", err)
+		}
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+		return fmt.Errorf("写入数据库结构版本失败: %v", err)
+	}
+	return nil
+}
+
+// insertSQLiteRun 写入一次运行的全部数据
+func insertSQLiteRun(tx *sql.Tx, rep *report.Report, withCommits bool) error {
+	meta := rep.Meta
+	parameters, err := json.Marshal(newJSONReport(rep).Run.Parameters)
+	if err != nil {
+		return fmt.Errorf("序列化运行参数失败: %v", err)
+	}
+	result, err := tx.Exec(`INSERT INTO runs (tool_version, instance_url, started_at, finished_at, duration_seconds,
+		period, start_date, end_date, contribution_mode, attribution, bucket, parameters)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		meta.ToolVersion, meta.GitLabURL, sqliteTime(meta.GeneratedAt), sqliteTime(meta.FinishedAt), meta.Duration().Seconds(),
+		meta.Period, meta.StartDate, meta.EndDate, meta.ContributionMode, meta.Attribution, meta.Bucket, string(parameters))
+	if err != nil {
+		return fmt.Errorf("写入运行记录失败: %v", err)
+	}
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取运行 ID 失败: %v", err)
+	}
+
+	// 包括没有提交的项目，以及只出现在统计结果中的项目
+	projectIDs := make(map[string]bool)
+	for _, project := range rep.Projects {
+		projectIDs[project.ID] = true
+	}
+	for _, stat := range rep.Stats {
+		for projectID := range stat.Projects {
+			projectIDs[projectID] = true
+		}
+	}
+	for _, projectID := range report.SortedKeys(projectIDs) {
+		project := rep.Project(projectID)
+		if _, err := tx.Exec("INSERT INTO projects (run_id, project_id, name, path) VALUES (?, ?, ?, ?)",
+			runID, projectID, project.Name, project.PathWithNamespace); err != nil {
+			return fmt.Errorf("写入项目失败: %v", err)
+		}
+	}
+
+	for _, user := range rep.SortedUsers() {
+		stat := rep.Stats[user]
+		if _, err := tx.Exec(`INSERT INTO users (run_id, user, commits, additions, deletions, changes, total, reverted)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			runID, user, stat.Commits, stat.Additions, stat.Deletions, stat.Changes, stat.Total, stat.Reverted); err != nil {
+			return fmt.Errorf("写入用户统计失败: %v", err)
+		}
+		for _, projectID := range report.SortedKeys(stat.Projects) {
+			s := stat.Projects[projectID]
+			if _, err := tx.Exec(`INSERT INTO user_project_stats (run_id, user, project_id, commits, additions, deletions, changes, total, reverted)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				runID, user, projectID, s.Commits, s.Additions, s.Deletions, s.Changes, s.Additions+s.Deletions, s.Reverted); err != nil {
+				return fmt.Errorf("写入用户项目统计失败: %v", err)
+			}
+		}
+	}
+
+	for _, bucket := range rep.Buckets {
+		for _, user := range report.SortedKeys(bucket.Stats) {
+			for _, projectID := range report.SortedKeys(bucket.Stats[user].Projects) {
+				s := bucket.Stats[user].Projects[projectID]
+				if _, err := tx.Exec(`INSERT INTO buckets (run_id, label, start_date, user, project_id, commits, additions, deletions, changes, total)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+					runID, bucket.Label, bucket.Start.Format("2006-01-02"), user, projectID,
+					s.Commits, s.Additions, s.Deletions, s.Changes, s.Additions+s.Deletions); err != nil {
+					return fmt.Errorf("写入时间区间统计失败: %v", err)
+				}
+			}
+		}
+	}
+
+	if withCommits {
+		if err := insertSQLiteCommits(tx, runID, rep.Commits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertSQLiteCommits 写入计入统计的提交明细，同一项目中重复的提交只写入一次
+func insertSQLiteCommits(tx *sql.Tx, runID int64, commits []gitlab.Commit) error {
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO commits (run_id, project_id, sha, author_name, author_email,
+		authored_at, committed_at, title, additions, deletions, changes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("写入提交明细失败: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, commit := range commits {
+		if _, err := stmt.Exec(runID, commit.ProjectID, commit.ID, commit.AuthorName, commit.AuthorEmail,
+			sqliteTime(commit.AuthoredDate), sqliteTime(commit.CommittedDate), commit.Subject(),
+			commit.Stats.Additions, commit.Stats.Deletions, commit.Stats.Total); err != nil {
+			return fmt.Errorf("写入提交明细失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// sqliteTime 将时间格式化为 SQLite 日期函数可以识别的 RFC 3339 文本
+func sqliteTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
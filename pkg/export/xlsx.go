@@ -0,0 +1,324 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+	"github.com/doufum/gitlab-analyze/pkg/report"
+	"github.com/xuri/excelize/v2"
+)
+
+// 工作表名称的最大长度
+const maxSheetNameLength = 31
+
+// 列宽范围（字符数）
+const (
+	minColumnWidth = 8
+	maxColumnWidth = 50
+)
+
+// sheetStyles 工作表使用的单元格样式
+type sheetStyles struct {
+	header  int
+	integer int
+	decimal int
+}
+
+// namedTable 写入指定工作表的结果表
+type namedTable struct {
+	name  string
+	table report.Table
+}
+
+// 图表工作表和图表引用的数据工作表名称
+const (
+	chartsSheet     = "Charts"
+	perUserSheet    = "Per-User"
+	perProjectSheet = "Per-Project"
+	trendSheet      = "Trend"
+)
+
+func init() {
+	Register("xlsx", xlsxExporter{})
+}
+
+// xlsxExporter 导出一个多工作表的 XLSX 文件
+type xlsxExporter struct{}
+
+// Export 导出统计结果到一个多工作表的 XLSX 文件
+func (xlsxExporter) Export(rep *report.Report, opts Options) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newSheetStyles(f)
+	if err != nil {
+		return err
+	}
+
+	// 固定的汇总工作表在前，附加结果表随后，运行元数据放在最后
+	sheets := []namedTable{
+		{"Summary", rep.SummaryTable()},
+		{perUserSheet, rep.UserTable()},
+		{perProjectSheet, rep.ProjectTable()},
+		{"User×Project", rep.MatrixTable()},
+	}
+	if len(rep.Buckets) > 0 {
+		sheets = append(sheets, namedTable{trendSheet, rep.TrendTable(opts.ChartTopN)})
+	}
+	for _, table := range rep.Tables() {
+		sheets = append(sheets, namedTable{table.Name, table})
+	}
+	sheets = append(sheets, namedTable{"Run Metadata", rep.Meta.Table()})
+
+	used := map[string]bool{strings.ToLower(chartsSheet): true}
+	for i, sheet := range sheets {
+		name := sheetName(sheet.name, used)
+		if i == 0 {
+			if err := f.SetSheetName(f.GetSheetName(0), name); err != nil {
+				return fmt.Errorf("重命名工作表失败: %v", err)
+			}
+		} else if _, err := f.NewSheet(name); err != nil {
+			return fmt.Errorf("创建工作表 %s 失败: %v", name, err)
+		}
+		if err := writeTableToSheet(f, name, sheet.table, styles); err != nil {
+			return err
+		}
+		// 图表工作表紧跟在汇总工作表之后
+		if i == 0 && opts.ChartTopN > 0 {
+			if _, err := f.NewSheet(chartsSheet); err != nil {
+				return fmt.Errorf("创建工作表 %s 失败: %v", chartsSheet, err)
+			}
+		}
+	}
+	if opts.ChartTopN > 0 {
+		if err := addCharts(f, rep, opts.ChartTopN); err != nil {
+			return err
+		}
+	}
+	f.SetActiveSheet(0)
+
+	if err := f.SaveAs(opts.Path(rep, "", "", "xlsx")); err != nil {
+		return fmt.Errorf("保存 XLSX 文件失败: %v", err)
+	}
+	return nil
+}
+
+// addCharts 在图表工作表中添加用户排名柱状图、项目增删堆积柱状图，
+// 启用时间分桶时添加代码量趋势折线图
+func addCharts(f *excelize.File, rep *report.Report, topN int) error {
+	dimension := excelize.ChartDimension{Width: 800, Height: 400}
+	// 每个图表占用的行数，与图表高度对应
+	rowsPerChart := 21
+	row := 1
+	add := func(chart *excelize.Chart) error {
+		chart.Dimension = dimension
+		if err := f.AddChart(chartsSheet, fmt.Sprintf("A%d", row), chart); err != nil {
+			return fmt.Errorf("添加图表失败: %v", err)
+		}
+		row += rowsPerChart
+		return nil
+	}
+
+	// 用户工作表已按总代码量降序排列，F 列为总代码量
+	if users := min(topN, len(rep.Stats)); users > 0 {
+		if err := add(&excelize.Chart{
+			Type: excelize.Col,
+			Series: []excelize.ChartSeries{{
+				Name:       sheetRange(perUserSheet, "F", 1, 1),
+				Categories: sheetRange(perUserSheet, "A", 2, users+1),
+				Values:     sheetRange(perUserSheet, "F", 2, users+1),
+			}},
+			Title:  []excelize.RichTextRun{{Text: fmt.Sprintf("总代码量前 %d 名用户", users)}},
+			Legend: excelize.ChartLegend{Position: "none"},
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 项目工作表已按总代码量降序排列，C 列为项目路径，E、F 列为增加和删除行数
+	if projects := min(topN, len(rep.ProjectTotals())); projects > 0 {
+		var series []excelize.ChartSeries
+		for _, column := range []string{"E", "F"} {
+			series = append(series, excelize.ChartSeries{
+				Name:       sheetRange(perProjectSheet, column, 1, 1),
+				Categories: sheetRange(perProjectSheet, "C", 2, projects+1),
+				Values:     sheetRange(perProjectSheet, column, 2, projects+1),
+			})
+		}
+		if err := add(&excelize.Chart{
+			Type:   excelize.ColStacked,
+			Series: series,
+			Title:  []excelize.RichTextRun{{Text: "各项目增加和删除行数"}},
+			Legend: excelize.ChartLegend{Position: "bottom"},
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 趋势工作表的第一列为区间，随后为各用户，最后一列为合计，合计不绘制以免压缩用户曲线
+	if len(rep.Buckets) > 0 {
+		trend := rep.TrendTable(topN)
+		var series []excelize.ChartSeries
+		for i := 1; i < len(trend.Header)-1; i++ {
+			column, _ := excelize.ColumnNumberToName(i + 1)
+			series = append(series, excelize.ChartSeries{
+				Name:       sheetRange(trendSheet, column, 1, 1),
+				Categories: sheetRange(trendSheet, "A", 2, len(trend.Rows)+1),
+				Values:     sheetRange(trendSheet, column, 2, len(trend.Rows)+1),
+				Marker:     excelize.ChartMarker{Symbol: "circle", Size: 5},
+			})
+		}
+		if len(series) > 0 {
+			if err := add(&excelize.Chart{
+				Type:   excelize.Line,
+				Series: series,
+				Title:  []excelize.RichTextRun{{Text: fmt.Sprintf("总代码量趋势（按%s）", bucketNames[rep.Meta.Bucket])}},
+				Legend: excelize.ChartLegend{Position: "bottom"},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 时间分桶粒度的中文描述
+var bucketNames = map[string]string{
+	gitlab.BucketWeek:  "周",
+	gitlab.BucketMonth: "月",
+}
+
+// sheetRange 返回图表引用的单元格区域
+func sheetRange(sheet, column string, from, to int) string {
+	if from == to {
+		return fmt.Sprintf("'%s'!$%s$%d", sheet, column, from)
+	}
+	return fmt.Sprintf("'%s'!$%s$%d:$%s$%d", sheet, column, from, column, to)
+}
+
+// newSheetStyles 创建表头和数字单元格的样式
+func newSheetStyles(f *excelize.File) (sheetStyles, error) {
+	var styles sheetStyles
+	var err error
+	styles.header, err = f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Vertical: "center"},
+	})
+	if err != nil {
+		return styles, fmt.Errorf("创建表头样式失败: %v", err)
+	}
+	// 内置格式 3 为 #,##0
+	styles.integer, err = f.NewStyle(&excelize.Style{NumFmt: 3})
+	if err != nil {
+		return styles, fmt.Errorf("创建整数样式失败: %v", err)
+	}
+	// 内置格式 4 为 #,##0.00
+	styles.decimal, err = f.NewStyle(&excelize.Style{NumFmt: 4})
+	if err != nil {
+		return styles, fmt.Errorf("创建小数样式失败: %v", err)
+	}
+	return styles, nil
+}
+
+// writeTableToSheet 将结果表写入工作表，冻结表头并设置数字格式、筛选和列宽
+func writeTableToSheet(f *excelize.File, sheet string, table report.Table, styles sheetStyles) error {
+	header := make([]interface{}, len(table.Header))
+	widths := make([]int, len(table.Header))
+	for i, name := range table.Header {
+		header[i] = name
+		widths[i] = textWidth(name)
+	}
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return fmt.Errorf("写入工作表 %s 表头失败: %v", sheet, err)
+	}
+
+	for r, row := range table.Rows {
+		cell, _ := excelize.CoordinatesToCellName(1, r+2)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return fmt.Errorf("写入工作表 %s 数据失败: %v", sheet, err)
+		}
+		for c, value := range row {
+			style := 0
+			switch value.(type) {
+			case int:
+				style = styles.integer
+			case float64:
+				style = styles.decimal
+			}
+			if style != 0 {
+				cell, _ := excelize.CoordinatesToCellName(c+1, r+2)
+				if err := f.SetCellStyle(sheet, cell, cell, style); err != nil {
+					return fmt.Errorf("设置工作表 %s 样式失败: %v", sheet, err)
+				}
+			}
+			if c < len(widths) {
+				widths[c] = max(widths[c], textWidth(formatCell(value)))
+			}
+		}
+	}
+
+	if len(table.Header) == 0 {
+		return nil
+	}
+	lastColumn, _ := excelize.ColumnNumberToName(len(table.Header))
+	if err := f.SetCellStyle(sheet, "A1", lastColumn+"1", styles.header); err != nil {
+		return fmt.Errorf("设置工作表 %s 表头样式失败: %v", sheet, err)
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return fmt.Errorf("冻结工作表 %s 表头失败: %v", sheet, err)
+	}
+	filterRange := fmt.Sprintf("A1:%s%d", lastColumn, len(table.Rows)+1)
+	if err := f.AutoFilter(sheet, filterRange, nil); err != nil {
+		return fmt.Errorf("设置工作表 %s 筛选失败: %v", sheet, err)
+	}
+	for i, width := range widths {
+		column, _ := excelize.ColumnNumberToName(i + 1)
+		width = min(max(width+2, minColumnWidth), maxColumnWidth)
+		if err := f.SetColWidth(sheet, column, column, float64(width)); err != nil {
+			return fmt.Errorf("设置工作表 %s 列宽失败: %v", sheet, err)
+		}
+	}
+	return nil
+}
+
+// sheetName 返回合法且不重复的工作表名称
+func sheetName(name string, used map[string]bool) string {
+	name = strings.NewReplacer(":", "_", "\\", "_", "/", "_", "?", "_", "*", "_", "[", "(", "]", ")").Replace(name)
+	base := truncateRunes(name, maxSheetNameLength)
+	name = base
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+		name = truncateRunes(base, maxSheetNameLength-len(suffix)) + suffix
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// textWidth 估算文本显示宽度，非 ASCII 字符按两个字符计算
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			width++
+		} else {
+			width += 2
+		}
+	}
+	return width
+}
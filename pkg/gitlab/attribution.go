@@ -0,0 +1,210 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"sync"
+)
+
+// 代码量的归属方式
+const (
+	// AttributionAll 统计所有分支上推送的提交
+	AttributionAll = "all"
+	// AttributionMergedMR 只统计合并到默认分支或受保护分支的合并请求中的提交
+	AttributionMergedMR = "merged-mr"
+)
+
+// ValidateAttribution 校验代码量归属方式
+func ValidateAttribution(mode string) error {
+	if mode == AttributionAll || mode == AttributionMergedMR {
+		return nil
+	}
+	return fmt.Errorf("无效的代码量归属方式: %s，可选值为 all、merged-mr", mode)
+}
+
+// GetProjectMergedCommits 获取时间范围内合并到默认分支或受保护分支的合并请求中的提交。
+// 压缩合并的合并请求只计入压缩后的提交，并归属于合并请求的作者；
+// 其余合并请求计入其包含的提交，合并提交本身不计入
+func (c *GitLabClient) GetProjectMergedCommits(projectID, startDate, endDate string) ([]Commit, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := c.targetBranches(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	since, _ := dateRangeParams(startDate, endDate)
+	mrs, err := getAllPages[MergeRequest](c, fmt.Sprintf("/projects/%s/merge_requests", projectID), map[string]string{
+		"scope":         "all",
+		"state":         MergeRequestMerged,
+		"updated_after": since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []MergeRequest
+	for _, mr := range mrs {
+		if mr.MergedAt == nil || !inWindow(*mr.MergedAt, start, end) || !matchesBranch(branches, mr.TargetBranch) {
+			continue
+		}
+		mr.ProjectID = projectID
+		merged = append(merged, mr)
+	}
+
+	// 并发获取每个合并请求的提交，结果按合并请求的顺序去重。
+	// 任一合并请求获取失败时整个项目视为失败，避免少计已上线的代码
+	perMR := make([][]Commit, len(merged))
+	errs := make([]error, len(merged))
+	var wg sync.WaitGroup
+	work := make(chan int)
+	workerCount := 5
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				commits, err := c.mergeRequestCommits(merged[index])
+				if err != nil {
+					errs[index] = fmt.Errorf("获取合并请求 !%d 的提交失败: %v", merged[index].IID, err)
+					continue
+				}
+				perMR[index] = commits
+			}
+		}()
+	}
+	for i := range merged {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []Commit
+	seen := make(map[string]bool)
+	for _, commits := range perMR {
+		for _, commit := range commits {
+			if seen[commit.ID] {
+				continue
+			}
+			seen[commit.ID] = true
+			result = append(result, commit)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CommittedDate.Before(result[j].CommittedDate)
+	})
+	return result, nil
+}
+
+// mergeRequestCommits 返回合并请求计入统计的提交
+func (c *GitLabClient) mergeRequestCommits(mr MergeRequest) ([]Commit, error) {
+	if mr.SquashCommitSHA != "" {
+		commit, err := c.getCommit(mr.ProjectID, mr.SquashCommitSHA)
+		if err != nil {
+			return nil, err
+		}
+		// 压缩后的提交归属于合并请求的作者，作者不同时邮箱不再适用
+		if commit.AuthorName != mr.AuthorName() {
+			commit.AuthorName = mr.AuthorName()
+			commit.AuthorEmail = ""
+		}
+		return []Commit{commit}, nil
+	}
+
+	listed, err := getAllPages[Commit](c, fmt.Sprintf("/projects/%s/merge_requests/%d/commits", mr.ProjectID, mr.IID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, item := range listed {
+		// 列表中已包含作者、日期和父提交，只缺少代码量；已缓存代码量的提交不再请求详情
+		if len(item.ParentIDs) > 1 {
+			continue
+		}
+		if stats, ok := c.cachedCommitStats(mr.ProjectID, item.ID); ok {
+			item.Stats = stats
+			item.ProjectID = mr.ProjectID
+			commits = append(commits, item)
+			continue
+		}
+		commit, err := c.getCommit(mr.ProjectID, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(commit.ParentIDs) > 1 {
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// getCommit 获取单个提交及其代码量
+func (c *GitLabClient) getCommit(projectID, sha string) (Commit, error) {
+	body, err := c.getWithRetry(fmt.Sprintf("/projects/%s/repository/commits/%s", projectID, sha), nil)
+	if err != nil {
+		return Commit{}, err
+	}
+	var commit Commit
+	if err := json.Unmarshal(body, &commit); err != nil {
+		return Commit{}, fmt.Errorf("解析提交 %s 详情失败: %v", sha, err)
+	}
+	c.cacheCommitStats(projectID, commit.ID, commit.Stats)
+	commit.ProjectID = projectID
+	return commit, nil
+}
+
+// targetBranches 返回项目的默认分支和受保护分支（受保护分支名称可以包含通配符）
+func (c *GitLabClient) targetBranches(projectID string) ([]string, error) {
+	body, err := c.getWithRetry(fmt.Sprintf("/projects/%s", url.PathEscape(projectID)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取项目 %s 信息失败: %v", projectID, err)
+	}
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := json.Unmarshal(body, &project); err != nil {
+		return nil, fmt.Errorf("解析项目 %s 信息失败: %v", projectID, err)
+	}
+
+	protected, err := getAllPages[struct {
+		Name string `json:"name"`
+	}](c, fmt.Sprintf("/projects/%s/protected_branches", projectID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	if project.DefaultBranch != "" {
+		branches = append(branches, project.DefaultBranch)
+	}
+	for _, branch := range protected {
+		branches = append(branches, branch.Name)
+	}
+	return branches, nil
+}
+
+// matchesBranch 判断分支是否为目标分支之一
+func matchesBranch(patterns []string, branch string) bool {
+	for _, pattern := range patterns {
+		if pattern == branch {
+			return true
+		}
+		if ok, err := path.Match(pattern, branch); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
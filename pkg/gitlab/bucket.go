@@ -0,0 +1,93 @@
+package gitlab
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 时间分桶的粒度
+const (
+	// BucketNone 不分桶
+	BucketNone = ""
+	// BucketWeek 按周分桶，每周从周一开始
+	BucketWeek = "week"
+	// BucketMonth 按自然月分桶
+	BucketMonth = "month"
+)
+
+// Bucket 一个时间区间内的用户统计
+type Bucket struct {
+	Start time.Time
+	// Label 区间名称，按月为 2006-01，按周为周一的日期
+	Label string
+	Stats map[string]UserStats
+}
+
+// ValidateBucket 校验时间分桶粒度
+func ValidateBucket(granularity string) error {
+	switch granularity {
+	case BucketNone, BucketWeek, BucketMonth:
+		return nil
+	}
+	return fmt.Errorf("无效的时间分桶粒度: %s，可选值为 week、month", granularity)
+}
+
+// BucketCommits 按提交时间将提交分到各时间区间并统计，区间按时间顺序排列，
+// 统计范围内没有提交的区间同样返回，便于连续展示。按合并请求归属时，合并请求中可能包含统计范围之前
+// 或之后的提交，这些提交计入第一个或最后一个区间，不会产生统计范围之外的区间
+func BucketCommits(commits []Commit, granularity, startDate, endDate string) ([]Bucket, error) {
+	if granularity == BucketNone {
+		return nil, nil
+	}
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[time.Time][]Commit)
+	for at := bucketStart(start, granularity); at.Before(end); at = nextBucket(at, granularity) {
+		grouped[at] = nil
+	}
+	last := end.Add(-time.Nanosecond)
+	for _, commit := range commits {
+		committed := commit.CommittedDate
+		if committed.Before(start) {
+			committed = start
+		} else if committed.After(last) {
+			committed = last
+		}
+		at := bucketStart(committed, granularity)
+		grouped[at] = append(grouped[at], commit)
+	}
+
+	buckets := make([]Bucket, 0, len(grouped))
+	for at, bucketCommits := range grouped {
+		label := at.Format("2006-01-02")
+		if granularity == BucketMonth {
+			label = at.Format("2006-01")
+		}
+		buckets = append(buckets, Bucket{Start: at, Label: label, Stats: CommitsToUserStats(bucketCommits)})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets, nil
+}
+
+// bucketStart 返回时间所在区间的开始时间（本地时间）
+func bucketStart(t time.Time, granularity string) time.Time {
+	if granularity == BucketMonth {
+		day := dayStart(t)
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.Local)
+	}
+	return weekStart(t)
+}
+
+// nextBucket 返回下一个区间的开始时间
+func nextBucket(at time.Time, granularity string) time.Time {
+	if granularity == BucketMonth {
+		return at.AddDate(0, 1, 0)
+	}
+	return at.AddDate(0, 0, 7)
+}
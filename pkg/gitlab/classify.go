@@ -0,0 +1,146 @@
+package gitlab
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 分类维度
+const (
+	DimensionType     = "type"
+	DimensionCategory = "category"
+)
+
+// 约定式提交之外的类型
+const (
+	TypeOther          = "other"
+	TypeUnconventional = "none"
+)
+
+// ConventionalTypes 单独统计的约定式提交类型，其余类型计入 other
+var ConventionalTypes = []string{"feat", "fix", "refactor", "docs", "test", "chore"}
+
+// 约定式提交标题：type(scope)!: description
+var conventionalPattern = regexp.MustCompile(`^([a-zA-Z]+)(\([^)]*\))?!?:\s*\S`)
+
+// Category 自定义的提交信息分类
+type Category struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Classifier 提交分类器
+type Classifier struct {
+	Categories []Category
+}
+
+// CategoryStats 某个分类下的提交数和代码量
+type CategoryStats struct {
+	Commits   int
+	Additions int
+	Deletions int
+}
+
+// CategoryBreakdown 用户在某个项目中某个分类的统计
+type CategoryBreakdown struct {
+	Dimension string
+	Category  string
+	User      string
+	ProjectID string
+	CategoryStats
+}
+
+// NewClassifier 根据 name=regex 格式的规则创建分类器
+func NewClassifier(specs []string) (*Classifier, error) {
+	c := &Classifier{}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, pattern, ok := strings.Cut(spec, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("分类规则格式无效，应为 名称=正则表达式: %s", spec)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("分类 %s 的正则表达式无效: %v", name, err)
+		}
+		c.Categories = append(c.Categories, Category{Name: strings.TrimSpace(name), Pattern: re})
+	}
+	return c, nil
+}
+
+// CommitType 返回提交的约定式提交类型
+func (c *Classifier) CommitType(commit Commit) string {
+	m := conventionalPattern.FindStringSubmatch(commit.Subject())
+	if m == nil {
+		return TypeUnconventional
+	}
+	commitType := strings.ToLower(m[1])
+	for _, t := range ConventionalTypes {
+		if commitType == t {
+			return commitType
+		}
+	}
+	return TypeOther
+}
+
+// MatchCategories 返回提交信息匹配的所有自定义分类
+func (c *Classifier) MatchCategories(commit Commit) []string {
+	var names []string
+	for _, category := range c.Categories {
+		if category.Pattern.MatchString(commit.Message) || category.Pattern.MatchString(commit.Title) {
+			names = append(names, category.Name)
+		}
+	}
+	return names
+}
+
+// Breakdown 按用户、项目统计每个类型和自定义分类的提交数和代码量，
+// 一个提交可以同时属于多个自定义分类
+func (c *Classifier) Breakdown(commits []Commit) []CategoryBreakdown {
+	index := make(map[[4]string]*CategoryBreakdown)
+	var result []*CategoryBreakdown
+
+	add := func(dimension, category string, commit Commit) {
+		key := [4]string{dimension, category, commit.AuthorName, commit.ProjectID}
+		b, exists := index[key]
+		if !exists {
+			b = &CategoryBreakdown{Dimension: dimension, Category: category, User: commit.AuthorName, ProjectID: commit.ProjectID}
+			index[key] = b
+			result = append(result, b)
+		}
+		b.Commits++
+		b.Additions += commit.Stats.Additions
+		b.Deletions += commit.Stats.Deletions
+	}
+
+	for _, commit := range commits {
+		add(DimensionType, c.CommitType(commit), commit)
+		for _, category := range c.MatchCategories(commit) {
+			add(DimensionCategory, category, commit)
+		}
+	}
+
+	breakdown := make([]CategoryBreakdown, 0, len(result))
+	for _, b := range result {
+		breakdown = append(breakdown, *b)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		a, b := breakdown[i], breakdown[j]
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		if a.ProjectID != b.ProjectID {
+			return a.ProjectID < b.ProjectID
+		}
+		if a.Dimension != b.Dimension {
+			return a.Dimension > b.Dimension
+		}
+		return a.Category < b.Category
+	})
+	return breakdown
+}
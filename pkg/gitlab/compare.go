@@ -0,0 +1,153 @@
+package gitlab
+
+import "sort"
+
+// 贡献者在两个时间段之间的状态
+const (
+	ContributorActive      = "active"
+	ContributorNew         = "new"
+	ContributorDisappeared = "disappeared"
+)
+
+// MetricDelta 单个指标在两个时间段之间的变化
+type MetricDelta struct {
+	Current  int
+	Previous int
+	Delta    int
+	// Percent 变化百分比，对比期为 0 时无意义，HasPercent 为 false
+	Percent    float64
+	HasPercent bool
+}
+
+// StatsDelta 一组代码量指标的变化
+type StatsDelta struct {
+	Status    string
+	Additions MetricDelta
+	Deletions MetricDelta
+	Changes   MetricDelta
+	Total     MetricDelta
+}
+
+// UserComparison 单个用户的对比结果
+type UserComparison struct {
+	User string
+	StatsDelta
+	Projects map[string]StatsDelta
+}
+
+// Comparison 两个时间段的对比结果
+type Comparison struct {
+	Users    []UserComparison
+	Projects map[string]StatsDelta
+}
+
+// newMetricDelta 计算指标变化
+func newMetricDelta(current, previous int) MetricDelta {
+	d := MetricDelta{
+		Current:  current,
+		Previous: previous,
+		Delta:    current - previous,
+	}
+	if previous != 0 {
+		d.Percent = float64(d.Delta) / float64(previous) * 100
+		d.HasPercent = true
+	}
+	return d
+}
+
+// newStatsDelta 根据两个时间段的统计数据计算变化
+func newStatsDelta(current, previous ProjectStats, inCurrent, inPrevious bool) StatsDelta {
+	status := ContributorActive
+	if !inPrevious {
+		status = ContributorNew
+	} else if !inCurrent {
+		status = ContributorDisappeared
+	}
+
+	return StatsDelta{
+		Status:    status,
+		Additions: newMetricDelta(current.Additions, previous.Additions),
+		Deletions: newMetricDelta(current.Deletions, previous.Deletions),
+		Changes:   newMetricDelta(current.Changes, previous.Changes),
+		Total:     newMetricDelta(current.Additions+current.Deletions, previous.Additions+previous.Deletions),
+	}
+}
+
+// CompareStats 对比两个时间段的合并统计结果（均为 MergeProjectStats 的输出）
+func CompareStats(current, previous map[string]UserStats) Comparison {
+	comparison := Comparison{Projects: make(map[string]StatsDelta)}
+
+	// 汇总所有出现过的用户
+	users := make(map[string]bool)
+	for user := range current {
+		users[user] = true
+	}
+	for user := range previous {
+		users[user] = true
+	}
+
+	// 项目级别的总计
+	currentProjects := make(map[string]ProjectStats)
+	previousProjects := make(map[string]ProjectStats)
+
+	for user := range users {
+		cur, inCurrent := current[user]
+		prev, inPrevious := previous[user]
+
+		userComparison := UserComparison{
+			User: user,
+			StatsDelta: newStatsDelta(
+				ProjectStats{Additions: cur.Additions, Deletions: cur.Deletions, Changes: cur.Changes},
+				ProjectStats{Additions: prev.Additions, Deletions: prev.Deletions, Changes: prev.Changes},
+				inCurrent, inPrevious,
+			),
+			Projects: make(map[string]StatsDelta),
+		}
+
+		projectIDs := make(map[string]bool)
+		for projectID := range cur.Projects {
+			projectIDs[projectID] = true
+		}
+		for projectID := range prev.Projects {
+			projectIDs[projectID] = true
+		}
+
+		for projectID := range projectIDs {
+			curProject, inCurrentProject := cur.Projects[projectID]
+			prevProject, inPreviousProject := prev.Projects[projectID]
+			userComparison.Projects[projectID] = newStatsDelta(curProject, prevProject, inCurrentProject, inPreviousProject)
+
+			currentProjects[projectID] = addProjectStats(currentProjects[projectID], curProject)
+			previousProjects[projectID] = addProjectStats(previousProjects[projectID], prevProject)
+		}
+
+		comparison.Users = append(comparison.Users, userComparison)
+	}
+
+	for projectID := range currentProjects {
+		cur := currentProjects[projectID]
+		prev := previousProjects[projectID]
+		comparison.Projects[projectID] = newStatsDelta(cur, prev, cur != ProjectStats{}, prev != ProjectStats{})
+	}
+
+	// 按当前总代码量降序排列，相同时按用户名排序
+	sort.Slice(comparison.Users, func(i, j int) bool {
+		a, b := comparison.Users[i], comparison.Users[j]
+		if a.Total.Current != b.Total.Current {
+			return a.Total.Current > b.Total.Current
+		}
+		return a.User < b.User
+	})
+
+	return comparison
+}
+
+// addProjectStats 累加项目统计数据
+func addProjectStats(a, b ProjectStats) ProjectStats {
+	a.Commits += b.Commits
+	a.Additions += b.Additions
+	a.Deletions += b.Deletions
+	a.Changes += b.Changes
+	a.Reverted += b.Reverted
+	return a
+}
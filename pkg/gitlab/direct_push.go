@@ -0,0 +1,125 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DirectPush 没有经过合并请求直接推送到默认分支或受保护分支的提交
+type DirectPush struct {
+	Commit
+	Branch string
+}
+
+// GetProjectDirectPushes 获取时间范围内直接推送到默认分支和受保护分支（不含通配符规则）的提交，
+// 通过 /repository/commits/:sha/merge_requests 判断提交是否属于某个合并请求
+func (c *GitLabClient) GetProjectDirectPushes(projectID, startDate, endDate string) ([]DirectPush, error) {
+	branches, err := c.targetBranches(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	since, until := dateRangeParams(startDate, endDate)
+	var candidates []DirectPush
+	seen := make(map[string]bool)
+	for _, branch := range branches {
+		if strings.ContainsAny(branch, "*?[") {
+			continue
+		}
+		commits, err := getAllPages[Commit](c, fmt.Sprintf("/projects/%s/repository/commits", projectID), map[string]string{
+			"ref_name":   branch,
+			"since":      since,
+			"until":      until,
+			"with_stats": "true",
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if seen[commit.ID] {
+				continue
+			}
+			seen[commit.ID] = true
+			commit.ProjectID = projectID
+			candidates = append(candidates, DirectPush{Commit: commit, Branch: branch})
+		}
+	}
+
+	// 并发检查每个提交关联的合并请求，任一提交检查失败时整个项目视为失败，避免少报直接推送
+	direct := make([]bool, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	work := make(chan int)
+	workerCount := 5
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				hasMR, err := c.commitHasMergeRequest(projectID, candidates[index].ID)
+				if err != nil {
+					errs[index] = fmt.Errorf("获取提交 %s 关联的合并请求失败: %v", candidates[index].ID, err)
+					continue
+				}
+				direct[index] = !hasMR
+			}
+		}()
+	}
+	for i := range candidates {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pushes []DirectPush
+	for i, push := range candidates {
+		if direct[i] {
+			pushes = append(pushes, push)
+		}
+	}
+	sort.SliceStable(pushes, func(i, j int) bool {
+		return pushes[i].CommittedDate.Before(pushes[j].CommittedDate)
+	})
+	return pushes, nil
+}
+
+// commitHasMergeRequest 判断提交是否属于某个合并请求
+func (c *GitLabClient) commitHasMergeRequest(projectID, sha string) (bool, error) {
+	body, err := c.getWithRetry(fmt.Sprintf("/projects/%s/repository/commits/%s/merge_requests", projectID, sha), nil)
+	if err != nil {
+		return false, err
+	}
+	var mrs []json.RawMessage
+	if err := json.Unmarshal(body, &mrs); err != nil {
+		return false, fmt.Errorf("解析提交 %s 关联的合并请求失败: %v", sha, err)
+	}
+	return len(mrs) > 0, nil
+}
+
+// FilterDirectPushesByAuthors 只保留目标用户的直接推送，目标用户为空时返回全部
+func FilterDirectPushesByAuthors(pushes []DirectPush, targetUsers []string) []DirectPush {
+	if len(targetUsers) == 0 {
+		return pushes
+	}
+
+	targetUsersMap := make(map[string]bool)
+	for _, user := range targetUsers {
+		targetUsersMap[user] = true
+	}
+
+	var filtered []DirectPush
+	for _, push := range pushes {
+		if targetUsersMap[push.AuthorName] {
+			filtered = append(filtered, push)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,310 @@
+package gitlab
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Deployment 环境部署记录
+type Deployment struct {
+	ID          int       `json:"id"`
+	IID         int       `json:"iid"`
+	Status      string    `json:"status"`
+	SHA         string    `json:"sha"`
+	Ref         string    `json:"ref"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Environment struct {
+		Name string `json:"name"`
+	} `json:"environment"`
+	Deployable struct {
+		FinishedAt *time.Time `json:"finished_at"`
+	} `json:"deployable"`
+
+	// 以下字段由统计流程填充
+	ProjectID string `json:"-"`
+	// 本次部署首次上线的合并请求从第一个提交到部署完成的时长
+	LeadTimes []time.Duration `json:"-"`
+	// 部署后到下一次部署前出现回滚提交或事故议题时视为失败的部署
+	Failed bool `json:"-"`
+}
+
+// FinishedAt 部署完成时间，部署作业没有完成时间时使用部署记录的更新时间
+func (d Deployment) FinishedAt() time.Time {
+	if d.Deployable.FinishedAt != nil {
+		return *d.Deployable.FinishedAt
+	}
+	return d.UpdatedAt
+}
+
+// DORAEvents 项目计算 DORA 指标所需的部署和事故
+type DORAEvents struct {
+	Deployments []Deployment
+	Incidents   []Issue
+}
+
+// DORAStats 一段时间内的 DORA 指标
+type DORAStats struct {
+	Days              int
+	Deployments       int
+	FailedDeployments int
+	LeadTimes         []time.Duration
+	Incidents         int
+	// 已关闭事故从创建到关闭的时长
+	RestoreTimes []time.Duration
+}
+
+// DeploymentFrequency 部署频率（次/天）
+func (s DORAStats) DeploymentFrequency() float64 {
+	if s.Days == 0 {
+		return 0
+	}
+	return float64(s.Deployments) / float64(s.Days)
+}
+
+// ChangeFailureRate 变更失败率（%）
+func (s DORAStats) ChangeFailureRate() float64 {
+	if s.Deployments == 0 {
+		return 0
+	}
+	return float64(s.FailedDeployments) / float64(s.Deployments) * 100
+}
+
+// DORAPoint 时间序列中一个区间的 DORA 指标
+type DORAPoint struct {
+	Start     time.Time
+	ProjectID string
+	DORAStats
+}
+
+// DORAReport DORA 指标汇总，项目 ID 为空表示全部项目
+type DORAReport struct {
+	Environment string
+	Summary     map[string]DORAStats
+	Daily       []DORAPoint
+	Weekly      []DORAPoint
+}
+
+// GetProjectDORAEvents 获取项目在时间范围内部署到指定环境的成功部署及其上线的合并请求，
+// 以及带有事故标签或类型为 incident 的议题
+func (c *GitLabClient) GetProjectDORAEvents(projectID, startDate, endDate, environment, incidentLabel string) (DORAEvents, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return DORAEvents{}, err
+	}
+	since, until := dateRangeParams(startDate, endDate)
+
+	// finished_after/finished_before 要求按完成时间排序且只查询成功的部署，finished_before 不含该时刻
+	all, err := getAllPages[Deployment](c, fmt.Sprintf("/projects/%s/deployments", projectID), map[string]string{
+		"environment":     environment,
+		"status":          "success",
+		"order_by":        "finished_at",
+		"finished_after":  since,
+		"finished_before": end.Format(time.RFC3339),
+	})
+	if err != nil {
+		return DORAEvents{}, err
+	}
+
+	var deployments []Deployment
+	for _, deployment := range all {
+		if inWindow(deployment.FinishedAt(), start, end) {
+			deployment.ProjectID = projectID
+			deployments = append(deployments, deployment)
+		}
+	}
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].FinishedAt().Before(deployments[j].FinishedAt())
+	})
+
+	// 合并请求只在第一次部署时计算前置时间。任一部署的合并请求或提交获取失败时整个项目视为失败，
+	// 避免只用部分合并请求计算前置时间
+	deployed := make(map[int]bool)
+	for i := range deployments {
+		deployment := &deployments[i]
+		mrs, err := getAllPages[MergeRequest](c, fmt.Sprintf("/projects/%s/deployments/%d/merge_requests", projectID, deployment.ID), nil)
+		if err != nil {
+			return DORAEvents{}, fmt.Errorf("获取部署 %d 的合并请求失败: %v", deployment.ID, err)
+		}
+		for _, mr := range mrs {
+			if deployed[mr.IID] {
+				continue
+			}
+			deployed[mr.IID] = true
+			mr.ProjectID = projectID
+			first, err := c.firstCommitAt(mr)
+			if err != nil {
+				return DORAEvents{}, fmt.Errorf("获取合并请求 !%d 的提交失败: %v", mr.IID, err)
+			}
+			deployment.LeadTimes = append(deployment.LeadTimes, deployment.FinishedAt().Sub(first))
+		}
+	}
+
+	// 事故：带事故标签或类型为 incident 的议题
+	var incidents []Issue
+	seen := make(map[int]bool)
+	queries := []map[string]string{{"issue_type": "incident"}}
+	if incidentLabel != "" {
+		queries = append(queries, map[string]string{"labels": incidentLabel})
+	}
+	for _, query := range queries {
+		query["created_after"] = since
+		query["created_before"] = until
+		issues, err := c.getProjectIssues(projectID, query)
+		if err != nil {
+			return DORAEvents{}, err
+		}
+		for _, issue := range issues {
+			if !seen[issue.ID] {
+				seen[issue.ID] = true
+				incidents = append(incidents, issue)
+			}
+		}
+	}
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].CreatedAt.Before(incidents[j].CreatedAt)
+	})
+
+	return DORAEvents{Deployments: deployments, Incidents: incidents}, nil
+}
+
+// firstCommitAt 返回合并请求中最早的提交时间，没有早于合并请求创建时间的提交时使用创建时间
+func (c *GitLabClient) firstCommitAt(mr MergeRequest) (time.Time, error) {
+	first := mr.CreatedAt
+	commits, err := getAllPages[Commit](c, fmt.Sprintf("/projects/%s/merge_requests/%d/commits", mr.ProjectID, mr.IID), nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, commit := range commits {
+		if !commit.AuthoredDate.IsZero() && commit.AuthoredDate.Before(first) {
+			first = commit.AuthoredDate
+		}
+	}
+	return first, nil
+}
+
+// markFailedDeployments 将部署完成后、下一次部署之前（最后一次部署到统计结束）
+// 出现回滚提交或事故的部署标记为失败
+func markFailedDeployments(events DORAEvents, projectID string, reverts []RevertPair, end time.Time) {
+	var failures []time.Time
+	for _, incident := range events.Incidents {
+		failures = append(failures, incident.CreatedAt)
+	}
+	for _, pair := range reverts {
+		if pair.Revert.ProjectID == projectID {
+			failures = append(failures, pair.Revert.CommittedDate)
+		}
+	}
+
+	for i := range events.Deployments {
+		from := events.Deployments[i].FinishedAt()
+		to := end
+		if i+1 < len(events.Deployments) {
+			to = events.Deployments[i+1].FinishedAt()
+		}
+		for _, at := range failures {
+			if inWindow(at, from, to) {
+				events.Deployments[i].Failed = true
+				break
+			}
+		}
+	}
+}
+
+// AnalyzeDORA 计算每个项目和全部项目的 DORA 指标，以及按天和按周的时间序列，
+// reverts 为统计范围内识别到的回滚提交，用于判断失败的部署
+func AnalyzeDORA(events map[string]DORAEvents, reverts []RevertPair, environment, startDate, endDate string) (DORAReport, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return DORAReport{}, err
+	}
+
+	report := DORAReport{Environment: environment, Summary: make(map[string]DORAStats)}
+	daily := make(map[[2]string]*DORAPoint)
+	weekly := make(map[[2]string]*DORAPoint)
+	days := int(end.Sub(start).Hours()/24 + 0.5)
+
+	// point 返回项目在某个区间的统计，项目 ID 为空表示全部项目
+	point := func(points map[[2]string]*DORAPoint, bucket time.Time, projectID string) *DORAPoint {
+		key := [2]string{bucket.Format("2006-01-02"), projectID}
+		if points[key] == nil {
+			points[key] = &DORAPoint{Start: bucket, ProjectID: projectID}
+		}
+		return points[key]
+	}
+	each := func(projectID string, at time.Time, fn func(s *DORAStats)) {
+		for _, id := range []string{projectID, ""} {
+			summary := report.Summary[id]
+			fn(&summary)
+			report.Summary[id] = summary
+			fn(&point(daily, dayStart(at), id).DORAStats)
+			fn(&point(weekly, weekStart(at), id).DORAStats)
+		}
+	}
+
+	// 全部项目的时间序列包含没有事件的区间，便于连续展示
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		point(daily, dayStart(day), "")
+		point(weekly, weekStart(day), "")
+	}
+
+	for projectID, projectEvents := range events {
+		markFailedDeployments(projectEvents, projectID, reverts, end)
+		for _, id := range []string{projectID, ""} {
+			summary := report.Summary[id]
+			summary.Days = days
+			report.Summary[id] = summary
+		}
+		for _, deployment := range projectEvents.Deployments {
+			each(projectID, deployment.FinishedAt(), func(s *DORAStats) {
+				s.Deployments++
+				if deployment.Failed {
+					s.FailedDeployments++
+				}
+				s.LeadTimes = append(s.LeadTimes, deployment.LeadTimes...)
+			})
+		}
+		for _, incident := range projectEvents.Incidents {
+			each(projectID, incident.CreatedAt, func(s *DORAStats) {
+				s.Incidents++
+				if incident.ClosedAt != nil {
+					s.RestoreTimes = append(s.RestoreTimes, incident.ClosedAt.Sub(incident.CreatedAt))
+				}
+			})
+		}
+	}
+
+	report.Daily = sortedPoints(daily, 1)
+	report.Weekly = sortedPoints(weekly, 7)
+	return report, nil
+}
+
+// sortedPoints 按区间开始时间和项目排序时间序列
+func sortedPoints(points map[[2]string]*DORAPoint, days int) []DORAPoint {
+	result := make([]DORAPoint, 0, len(points))
+	for _, p := range points {
+		p.Days = days
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Start.Equal(result[j].Start) {
+			return result[i].Start.Before(result[j].Start)
+		}
+		return result[i].ProjectID < result[j].ProjectID
+	})
+	return result
+}
+
+// dayStart 返回当天零点（本地时间）
+func dayStart(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// weekStart 返回所在周的周一零点（本地时间）
+func weekStart(t time.Time) time.Time {
+	day := dayStart(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
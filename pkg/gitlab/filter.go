@@ -0,0 +1,240 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 排除规则匹配的字段
+const (
+	FieldAuthorName  = "name"
+	FieldAuthorEmail = "email"
+)
+
+// 内置的常见机器人和服务账号作者名称规则
+var builtinBotNamePatterns = []string{
+	`(?i)\[bot\]$`,
+	`(?i)^renovate`,
+	`(?i)^dependabot`,
+	`(?i)^gitlab$`,
+	`(?i)^gitlab[ _-]?(ci|bot|runner)`,
+	`(?i)^(project|group)_\d+_bot`,
+	`(?i)^semantic-release`,
+	`(?i)^github-actions`,
+	`(?i)^snyk-bot$`,
+	`(?i)(ci|release|deploy)[ _-]?bot$`,
+}
+
+// 内置的常见机器人和服务账号邮箱规则
+var builtinBotEmailPatterns = []string{
+	`(?i)^bot@renovateapp\.com$`,
+	`(?i)^(project|group)_?\d+_bot.*@noreply\.`,
+	`(?i)\[bot\]@users\.noreply\.`,
+	`(?i)^dependabot`,
+}
+
+// ExclusionRule 作者排除规则
+type ExclusionRule struct {
+	Field   string
+	Pattern *regexp.Regexp
+	// Builtin 是否为内置规则
+	Builtin bool
+}
+
+// String 返回规则的可读描述
+func (r ExclusionRule) String() string {
+	source := "自定义"
+	if r.Builtin {
+		source = "内置"
+	}
+	field := "作者"
+	if r.Field == FieldAuthorEmail {
+		field = "邮箱"
+	}
+	return fmt.Sprintf("%s%s规则 %s", source, field, r.Pattern)
+}
+
+// AuthorFilter 按作者名称、邮箱和 GitLab 机器人标记排除提交
+type AuthorFilter struct {
+	Rules []ExclusionRule
+
+	// 不为 nil 时通过 GitLab 用户接口检查 bot 标记
+	client *GitLabClient
+	// 作者的排除原因缓存，空字符串表示保留
+	reasons map[string]string
+}
+
+// ExcludedAuthor 被排除作者的汇总
+type ExcludedAuthor struct {
+	Author string
+	Email  string
+	Reason string
+	UserStats
+}
+
+// NewAuthorFilter 根据名称和邮箱正则创建作者过滤器，builtin 为 true 时追加内置的机器人规则
+func NewAuthorFilter(namePatterns, emailPatterns []string, builtin bool) (*AuthorFilter, error) {
+	f := &AuthorFilter{reasons: make(map[string]string)}
+
+	add := func(field string, patterns []string, isBuiltin bool) error {
+		for _, pattern := range patterns {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("排除规则 %s 无效: %v", pattern, err)
+			}
+			f.Rules = append(f.Rules, ExclusionRule{Field: field, Pattern: re, Builtin: isBuiltin})
+		}
+		return nil
+	}
+
+	if err := add(FieldAuthorName, namePatterns, false); err != nil {
+		return nil, err
+	}
+	if err := add(FieldAuthorEmail, emailPatterns, false); err != nil {
+		return nil, err
+	}
+	if builtin {
+		add(FieldAuthorName, builtinBotNamePatterns, true)
+		add(FieldAuthorEmail, builtinBotEmailPatterns, true)
+	}
+	return f, nil
+}
+
+// CheckBotFlag 启用 GitLab 用户 bot 标记检查
+func (f *AuthorFilter) CheckBotFlag(client *GitLabClient) {
+	f.client = client
+}
+
+// Enabled 判断过滤器是否有任何规则
+func (f *AuthorFilter) Enabled() bool {
+	return len(f.Rules) > 0 || f.client != nil
+}
+
+// reason 返回作者被排除的原因，保留时返回空字符串
+func (f *AuthorFilter) reason(name, email string) string {
+	key := name + "\x00" + email
+	if reason, ok := f.reasons[key]; ok {
+		return reason
+	}
+
+	reason := ""
+	for _, rule := range f.Rules {
+		value := name
+		if rule.Field == FieldAuthorEmail {
+			value = email
+		}
+		if value != "" && rule.Pattern.MatchString(value) {
+			reason = rule.String()
+			break
+		}
+	}
+
+	if reason == "" && f.client != nil {
+		isBot, err := f.client.IsBotUser(name, email)
+		if err != nil {
+			fmt.Printf("警告: 检查用户 %s 的 bot 标记失败: %v\n", name, err)
+		} else if isBot {
+			reason = "GitLab bot 用户"
+		}
+	}
+
+	f.reasons[key] = reason
+	return reason
+}
+
+// Filter 将提交分为保留和排除两部分，并按作者汇总被排除的提交
+func (f *AuthorFilter) Filter(commits []Commit) ([]Commit, []ExcludedAuthor) {
+	var kept []Commit
+	excludedCommits := make(map[string][]Commit)
+	excludedInfo := make(map[string]ExcludedAuthor)
+
+	for _, commit := range commits {
+		reason := f.reason(commit.AuthorName, commit.AuthorEmail)
+		if reason == "" {
+			kept = append(kept, commit)
+			continue
+		}
+		excludedCommits[commit.AuthorName] = append(excludedCommits[commit.AuthorName], commit)
+		if _, exists := excludedInfo[commit.AuthorName]; !exists {
+			excludedInfo[commit.AuthorName] = ExcludedAuthor{Author: commit.AuthorName, Email: commit.AuthorEmail, Reason: reason}
+		}
+	}
+
+	var excluded []ExcludedAuthor
+	for author, authorCommits := range excludedCommits {
+		info := excludedInfo[author]
+		info.UserStats = CommitsToUserStats(authorCommits)[author]
+		excluded = append(excluded, info)
+	}
+	sort.Slice(excluded, func(i, j int) bool {
+		return excluded[i].Author < excluded[j].Author
+	})
+
+	return kept, excluded
+}
+
+// ExcludesUser 判断 GitLab 用户是否被排除，显示名称或用户名匹配任一规则即排除
+func (f *AuthorFilter) ExcludesUser(user User) bool {
+	return f.reason(user.DisplayName(), "") != "" || f.reason(user.Username, "") != ""
+}
+
+// FilterMergeRequests 排除机器人和服务账号创建的合并请求
+func (f *AuthorFilter) FilterMergeRequests(mrs []MergeRequest) []MergeRequest {
+	var kept []MergeRequest
+	for _, mr := range mrs {
+		if !f.ExcludesUser(mr.Author) {
+			kept = append(kept, mr)
+		}
+	}
+	return kept
+}
+
+// IsBotUser 通过 GitLab 用户接口判断作者是否为 bot 用户。先按邮箱搜索，没有找到匹配的用户时再按名称搜索
+// （非管理员令牌无法按私有邮箱和 noreply 邮箱搜索到用户）。搜索结果是模糊匹配，
+// 只有用户名、名称或公开邮箱与作者完全一致（不区分大小写）的用户才视为该作者
+func (c *GitLabClient) IsBotUser(name, email string) (bool, error) {
+	var searches []string
+	for _, search := range []string{email, name} {
+		if search != "" && (len(searches) == 0 || searches[0] != search) {
+			searches = append(searches, search)
+		}
+	}
+
+	for _, search := range searches {
+		body, err := c.doRequest("GET", "/users", map[string]string{"search": search})
+		if err != nil {
+			return false, err
+		}
+
+		var users []struct {
+			Username    string `json:"username"`
+			Name        string `json:"name"`
+			PublicEmail string `json:"public_email"`
+			Bot         bool   `json:"bot"`
+		}
+		if err := json.Unmarshal(body, &users); err != nil {
+			return false, fmt.Errorf("解析用户数据失败: %v", err)
+		}
+
+		found := false
+		for _, user := range users {
+			matched := (name != "" && (strings.EqualFold(user.Name, name) || strings.EqualFold(user.Username, name))) ||
+				(email != "" && strings.EqualFold(user.PublicEmail, email))
+			if matched && user.Bot {
+				return true, nil
+			}
+			found = found || matched
+		}
+		if found {
+			return false, nil
+		}
+	}
+	return false, nil
+}
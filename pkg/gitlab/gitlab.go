@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,6 +21,10 @@ type GitLabClient struct {
 	baseURL    string
 	token      string
 	httpClient *http.Client
+
+	// 提交详情缓存，键为 项目ID/提交SHA，时间范围重叠时避免重复请求
+	cacheMu     sync.Mutex
+	commitCache map[string]CommitStats
 }
 
 // 提交统计信息
@@ -30,11 +36,27 @@ type CommitStats struct {
 
 // 提交信息
 type Commit struct {
-	ID         string      `json:"id"`
-	AuthorName string      `json:"author_name"`
-	Stats      CommitStats `json:"stats"`
-	ParentIDs  []string    `json:"parent_ids"`
-	Message    string      `json:"message"`
+	ID            string      `json:"id"`
+	AuthorName    string      `json:"author_name"`
+	AuthorEmail   string      `json:"author_email"`
+	AuthoredDate  time.Time   `json:"authored_date"`
+	CommittedDate time.Time   `json:"committed_date"`
+	Stats         CommitStats `json:"stats"`
+	ParentIDs     []string    `json:"parent_ids"`
+	Title         string      `json:"title"`
+	Message       string      `json:"message"`
+
+	// 提交所属的项目 ID，由统计流程填充
+	ProjectID string `json:"-"`
+}
+
+// Subject 返回提交标题，没有标题时取提交信息的第一行
+func (c Commit) Subject() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	title, _, _ := strings.Cut(c.Message, "\n")
+	return strings.TrimSpace(title)
 }
 
 // CommitIdentifier 用于标识相同的提交
@@ -46,18 +68,31 @@ type CommitIdentifier struct {
 
 // 项目统计信息
 type ProjectStats struct {
+	Commits   int
 	Additions int
 	Deletions int
 	Changes   int
+	// 被回滚的代码行数
+	Reverted int
 }
 
 // 用户统计信息
 type UserStats struct {
+	Commits   int
 	Additions int
 	Deletions int
 	Changes   int
 	Total     int
-	Projects  map[string]ProjectStats
+	// 被回滚的代码行数
+	Reverted int
+	Projects map[string]ProjectStats
+}
+
+// ProjectInfo 项目信息
+type ProjectInfo struct {
+	ID                string
+	Name              string
+	PathWithNamespace string
 }
 
 // NewGitLabClient 创建新的 GitLab 客户端
@@ -68,26 +103,42 @@ func NewGitLabClient() *GitLabClient {
 	}
 
 	return &GitLabClient{
-		baseURL:    fmt.Sprintf("%s/api/%s", os.Getenv("GITLAB_URL"), os.Getenv("API_VERSION")),
-		token:      os.Getenv("GITLAB_TOKEN"),
-		httpClient: &http.Client{Transport: tr},
+		baseURL:     fmt.Sprintf("%s/api/%s", os.Getenv("GITLAB_URL"), os.Getenv("API_VERSION")),
+		token:       os.Getenv("GITLAB_TOKEN"),
+		httpClient:  &http.Client{Transport: tr},
+		commitCache: make(map[string]CommitStats),
 	}
 }
 
+// cachedCommitStats 从缓存中获取提交统计信息
+func (c *GitLabClient) cachedCommitStats(projectID, sha string) (CommitStats, bool) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	stats, ok := c.commitCache[projectID+"/"+sha]
+	return stats, ok
+}
+
+// cacheCommitStats 缓存提交统计信息
+func (c *GitLabClient) cacheCommitStats(projectID, sha string, stats CommitStats) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.commitCache[projectID+"/"+sha] = stats
+}
+
 // doRequest 发送 HTTP 请求到 GitLab API
 func (c *GitLabClient) doRequest(method, path string, params map[string]string) ([]byte, error) {
 	// 构建完整的 URL
-	url := c.baseURL + path
+	reqURL := c.baseURL + path
 	if len(params) > 0 {
 		queryParams := make([]string, 0, len(params))
 		for k, v := range params {
-			queryParams = append(queryParams, fmt.Sprintf("%s=%s", k, v))
+			queryParams = append(queryParams, fmt.Sprintf("%s=%s", k, url.QueryEscape(v)))
 		}
-		url += "?" + strings.Join(queryParams, "&")
+		reqURL += "?" + strings.Join(queryParams, "&")
 	}
 
 	// 创建请求
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequest(method, reqURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -116,10 +167,63 @@ func (c *GitLabClient) doRequest(method, path string, params map[string]string)
 	return body, nil
 }
 
+// getWithRetry 发送 GET 请求，失败时按指数退避重试
+func (c *GitLabClient) getWithRetry(path string, params map[string]string) ([]byte, error) {
+	maxRetries := 5
+	retryDelay := 1 * time.Second
+	var body []byte
+	var err error
+	for retry := 0; retry < maxRetries; retry++ {
+		if retry > 0 {
+			time.Sleep(retryDelay)
+			retryDelay *= 2 // 指数退避
+		}
+		body, err = c.doRequest("GET", path, params)
+		if err == nil {
+			return body, nil
+		}
+	}
+	return nil, err
+}
+
+// getAllPages 获取分页接口的全部数据
+func getAllPages[T any](c *GitLabClient, path string, params map[string]string) ([]T, error) {
+	query := map[string]string{"per_page": "100"}
+	for k, v := range params {
+		query[k] = v
+	}
+
+	var all []T
+	for page := 1; ; page++ {
+		query["page"] = strconv.Itoa(page)
+		body, err := c.getWithRetry(path, query)
+		if err != nil {
+			return nil, fmt.Errorf("请求 %s 失败（第 %d 页）: %v", path, page, err)
+		}
+		var items []T
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("解析 %s 数据失败（第 %d 页）: %v", path, page, err)
+		}
+		all = append(all, items...)
+		if len(items) < 100 {
+			return all, nil
+		}
+	}
+}
+
 // GetProjectCommitStats 获取项目提交统计信息
 func (c *GitLabClient) GetProjectCommitStats(projectID, startDate, endDate string) (map[string]UserStats, error) {
-    // 用于存储统计结果
-    stats := make(map[string]UserStats)
+	commits, err := c.GetProjectCommits(projectID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return CommitsToUserStats(commits), nil
+}
+
+// GetProjectCommits 获取项目在时间范围内的提交及其代码量，已过滤重复提交和合并提交
+func (c *GitLabClient) GetProjectCommits(projectID, startDate, endDate string) ([]Commit, error) {
+    // 用于存储去重后的提交
+    var result []Commit
     processedCommits := make(map[string]bool)
     // 用于检测重复提交
     commitSignatures := make(map[CommitIdentifier]bool)
@@ -151,6 +255,13 @@ func (c *GitLabClient) GetProjectCommitStats(projectID, startDate, endDate strin
 		go func(workerID int) {
 			defer wg.Done()
 			for commit := range commitChan {
+				// 优先使用缓存的提交详情
+				if cached, ok := c.cachedCommitStats(projectID, commit.ID); ok {
+					resultChan <- commitWork{commit: commit, stats: cached}
+					atomic.AddInt32(&processedCount, 1)
+					continue
+				}
+
 				// 获取提交详情
 				detailPath := fmt.Sprintf("/projects/%s/repository/commits/%s", projectID, commit.ID)
 
@@ -187,6 +298,7 @@ func (c *GitLabClient) GetProjectCommitStats(projectID, startDate, endDate strin
 					continue
 				}
 
+				c.cacheCommitStats(projectID, commit.ID, commitDetail.Stats)
 				resultChan <- commitWork{commit: commit, stats: commitDetail.Stats}
 				// 需要在文件顶部添加 "sync/atomic" 包导入
 				// 这里使用 atomic 包来原子递增已处理的提交计数
@@ -207,14 +319,15 @@ func (c *GitLabClient) GetProjectCommitStats(projectID, startDate, endDate strin
 	}()
 
 	// 启动提交获取 goroutine
+	since, until := dateRangeParams(startDate, endDate)
 	go func() {
 		defer close(commitChan)
 
 		page := 1
 		for {
 			params := map[string]string{
-				"since":    startDate,
-				"until":    endDate,
+				"since":    since,
+				"until":    until,
 				"all":      "true",
 				"per_page": "100", // 增加每页数量
 				"page":     fmt.Sprintf("%d", page),
@@ -313,32 +426,10 @@ func (c *GitLabClient) GetProjectCommitStats(projectID, startDate, endDate strin
 	
 		// 记录已处理的提交
 		processedCommits[commit.ID] = true
-	
-		// 更新统计信息
-		if _, exists := stats[commit.AuthorName]; !exists {
-			stats[commit.AuthorName] = UserStats{
-				Projects: make(map[string]ProjectStats),
-			}
-		}
-	
-		userStats := stats[commit.AuthorName]
-		userStats.Additions += work.stats.Additions
-		userStats.Deletions += work.stats.Deletions
-		userStats.Changes += work.stats.Total
-		userStats.Total += work.stats.Additions + work.stats.Deletions
-	
-		// 更新项目统计信息
-		if _, exists := userStats.Projects[projectID]; !exists {
-			userStats.Projects[projectID] = ProjectStats{}
-		}
-	
-		projectStats := userStats.Projects[projectID]
-		projectStats.Additions += work.stats.Additions
-		projectStats.Deletions += work.stats.Deletions
-		projectStats.Changes += work.stats.Total
-	
-		userStats.Projects[projectID] = projectStats
-		stats[commit.AuthorName] = userStats
+
+		commit.Stats = work.stats
+		commit.ProjectID = projectID
+		result = append(result, commit)
 	}
 
 	// 检查是否有致命错误发生
@@ -349,7 +440,54 @@ func (c *GitLabClient) GetProjectCommitStats(projectID, startDate, endDate strin
 		// 没有错误，继续处理
 	}
 
-    return stats, nil
+	// 按提交时间排序，保证后续处理顺序稳定
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CommittedDate.Before(result[j].CommittedDate)
+	})
+
+    return result, nil
+}
+
+// CommitsToUserStats 按作者汇总提交的代码量
+func CommitsToUserStats(commits []Commit) map[string]UserStats {
+	stats := make(map[string]UserStats)
+	for _, commit := range commits {
+		userStats, exists := stats[commit.AuthorName]
+		if !exists {
+			userStats = UserStats{Projects: make(map[string]ProjectStats)}
+		}
+		userStats.Commits++
+		userStats.Additions += commit.Stats.Additions
+		userStats.Deletions += commit.Stats.Deletions
+		userStats.Changes += commit.Stats.Total
+		userStats.Total += commit.Stats.Additions + commit.Stats.Deletions
+
+		// 更新项目统计信息
+		projectStats := userStats.Projects[commit.ProjectID]
+		projectStats.Commits++
+		projectStats.Additions += commit.Stats.Additions
+		projectStats.Deletions += commit.Stats.Deletions
+		projectStats.Changes += commit.Stats.Total
+		userStats.Projects[commit.ProjectID] = projectStats
+
+		stats[commit.AuthorName] = userStats
+	}
+	return stats
+}
+
+// dateRangeParams 将包含首尾的日期范围转换为 GitLab API 的 since/until 参数
+// 结束日期取当天的最后一秒，避免漏掉结束当天的提交
+func dateRangeParams(startDate, endDate string) (string, string) {
+	since, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return startDate, endDate
+	}
+	until, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return startDate, endDate
+	}
+	until = until.AddDate(0, 0, 1).Add(-time.Second)
+	return since.Format(time.RFC3339), until.Format(time.RFC3339)
 }
 
 // GetProjects 获取项目列表
@@ -453,10 +591,12 @@ func MergeProjectStats(projectsStats []map[string]UserStats, targetUsers []strin
 			}
 
 			userStats := mergedStats[author]
+			userStats.Commits += data.Commits
 			userStats.Additions += data.Additions
 			userStats.Deletions += data.Deletions
 			userStats.Changes += data.Changes
 			userStats.Total += data.Total
+			userStats.Reverted += data.Reverted
 			mergedStats[author] = userStats
 
 			// 合并项目级别的统计数据
@@ -466,13 +606,35 @@ func MergeProjectStats(projectsStats []map[string]UserStats, targetUsers []strin
 				}
 
 				projectStats := mergedStats[author].Projects[projectID]
+				projectStats.Commits += projectData.Commits
 				projectStats.Additions += projectData.Additions
 				projectStats.Deletions += projectData.Deletions
 				projectStats.Changes += projectData.Changes
+				projectStats.Reverted += projectData.Reverted
 				mergedStats[author].Projects[projectID] = projectStats
 			}
 		}
 	}
 
 	return mergedStats
-}
\ No newline at end of file
+}
+
+// FilterCommitsByAuthors 只保留目标用户的提交，目标用户为空时返回全部提交
+func FilterCommitsByAuthors(commits []Commit, targetUsers []string) []Commit {
+	if len(targetUsers) == 0 {
+		return commits
+	}
+
+	targetUsersMap := make(map[string]bool)
+	for _, user := range targetUsers {
+		targetUsersMap[user] = true
+	}
+
+	var filtered []Commit
+	for _, commit := range commits {
+		if targetUsersMap[commit.AuthorName] {
+			filtered = append(filtered, commit)
+		}
+	}
+	return filtered
+}
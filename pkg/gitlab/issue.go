@@ -0,0 +1,173 @@
+package gitlab
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 没有负责人或里程碑的议题的分组名称
+const (
+	NoAssignee  = "(未分配)"
+	NoMilestone = "(无里程碑)"
+)
+
+// Issue 议题信息
+type Issue struct {
+	ID        int      `json:"id"`
+	IID       int      `json:"iid"`
+	Title     string   `json:"title"`
+	State     string   `json:"state"`
+	IssueType string   `json:"issue_type"`
+	Labels    []string `json:"labels"`
+	Author    User     `json:"author"`
+	Assignees []User   `json:"assignees"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	TimeStats struct {
+		// 预估和已用时间（秒）
+		TimeEstimate   int `json:"time_estimate"`
+		TotalTimeSpent int `json:"total_time_spent"`
+	} `json:"time_stats"`
+	WebURL string `json:"web_url"`
+
+	// 议题所属的项目 ID，由统计流程填充
+	ProjectID string `json:"-"`
+}
+
+// getProjectIssues 获取项目中符合条件的议题
+func (c *GitLabClient) getProjectIssues(projectID string, params map[string]string) ([]Issue, error) {
+	query := map[string]string{"scope": "all"}
+	for k, v := range params {
+		query[k] = v
+	}
+	issues, err := getAllPages[Issue](c, fmt.Sprintf("/projects/%s/issues", projectID), query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		issues[i].ProjectID = projectID
+	}
+	return issues, nil
+}
+
+// IssueStats 议题的汇总统计
+type IssueStats struct {
+	// 统计范围内创建、关闭的议题数
+	Opened int
+	Closed int
+	// 统计范围内关闭的议题从创建到关闭的时长
+	CycleTimes []time.Duration
+	// 统计范围内创建或关闭的议题的预估时间和累计已用时间（秒）。
+	// 已用时间是议题全部工时记录之和，包括统计范围之外记录的工时
+	TimeEstimate int
+	TimeSpent    int
+}
+
+// IssueReport 按负责人、项目、标签和里程碑汇总的议题统计
+type IssueReport struct {
+	Assignees map[string]IssueStats
+	// 负责人在各项目中的统计，键为 [负责人, 项目 ID]
+	AssigneeProjects map[[2]string]IssueStats
+	Projects         map[string]IssueStats
+	Labels           map[string]IssueStats
+	Milestones       map[string]IssueStats
+}
+
+// GetProjectIssues 获取项目在时间范围内创建或关闭的议题
+func (c *GitLabClient) GetProjectIssues(projectID, startDate, endDate string) ([]Issue, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	since, _ := dateRangeParams(startDate, endDate)
+	all, err := c.getProjectIssues(projectID, map[string]string{"updated_after": since})
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, issue := range all {
+		if inWindow(issue.CreatedAt, start, end) || (issue.ClosedAt != nil && inWindow(*issue.ClosedAt, start, end)) {
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].CreatedAt.Before(issues[j].CreatedAt)
+	})
+	return issues, nil
+}
+
+// assignees 返回议题的负责人，没有负责人时返回名称为 NoAssignee 的用户
+func (issue Issue) assignees() []User {
+	if len(issue.Assignees) == 0 {
+		return []User{{Name: NoAssignee}}
+	}
+	return issue.Assignees
+}
+
+// SummarizeIssues 按负责人、项目、标签和里程碑汇总议题。
+// 有多个负责人、多个标签的议题完整计入每个负责人和标签；
+// targetUsers 不为空时负责人统计只包含目标用户，其余维度包含全部议题
+func SummarizeIssues(issues []Issue, startDate, endDate string, targetUsers []string) (IssueReport, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return IssueReport{}, err
+	}
+
+	targetUsersMap := make(map[string]bool)
+	for _, user := range targetUsers {
+		targetUsersMap[user] = true
+	}
+
+	report := IssueReport{
+		Assignees:        make(map[string]IssueStats),
+		AssigneeProjects: make(map[[2]string]IssueStats),
+		Projects:         make(map[string]IssueStats),
+		Labels:           make(map[string]IssueStats),
+		Milestones:       make(map[string]IssueStats),
+	}
+	for _, issue := range issues {
+		report.Projects[issue.ProjectID] = addIssue(report.Projects[issue.ProjectID], issue, start, end)
+
+		for _, user := range issue.assignees() {
+			// 与合并请求和评审的筛选相同，目标用户可以是名称或用户名
+			assignee := user.DisplayName()
+			if len(targetUsersMap) > 0 && !targetUsersMap[assignee] && !targetUsersMap[user.Username] {
+				continue
+			}
+			key := [2]string{assignee, issue.ProjectID}
+			report.Assignees[assignee] = addIssue(report.Assignees[assignee], issue, start, end)
+			report.AssigneeProjects[key] = addIssue(report.AssigneeProjects[key], issue, start, end)
+		}
+
+		for _, label := range issue.Labels {
+			report.Labels[label] = addIssue(report.Labels[label], issue, start, end)
+		}
+
+		milestone := NoMilestone
+		if issue.Milestone != nil && issue.Milestone.Title != "" {
+			milestone = issue.Milestone.Title
+		}
+		report.Milestones[milestone] = addIssue(report.Milestones[milestone], issue, start, end)
+	}
+	return report, nil
+}
+
+// addIssue 将议题计入汇总
+func addIssue(s IssueStats, issue Issue, start, end time.Time) IssueStats {
+	if inWindow(issue.CreatedAt, start, end) {
+		s.Opened++
+	}
+	if issue.ClosedAt != nil && inWindow(*issue.ClosedAt, start, end) {
+		s.Closed++
+		s.CycleTimes = append(s.CycleTimes, issue.ClosedAt.Sub(issue.CreatedAt))
+	}
+	s.TimeEstimate += issue.TimeStats.TimeEstimate
+	s.TimeSpent += issue.TimeStats.TotalTimeSpent
+	return s
+}
@@ -0,0 +1,353 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 合并请求状态
+const (
+	MergeRequestOpened = "opened"
+	MergeRequestMerged = "merged"
+	MergeRequestClosed = "closed"
+)
+
+// User GitLab 用户的基本信息
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// DisplayName 用户的显示名称，与提交作者名称对应
+func (u User) DisplayName() string {
+	if u.Name != "" {
+		return u.Name
+	}
+	return u.Username
+}
+
+// Note 合并请求的评论或系统记录
+type Note struct {
+	ID        int       `json:"id"`
+	Body      string    `json:"body"`
+	Author    User      `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	System    bool      `json:"system"`
+}
+
+// MergeRequest 合并请求信息
+type MergeRequest struct {
+	ID              int        `json:"id"`
+	IID             int        `json:"iid"`
+	Title           string     `json:"title"`
+	State           string     `json:"state"`
+	Author          User       `json:"author"`
+	SourceBranch    string     `json:"source_branch"`
+	TargetBranch    string     `json:"target_branch"`
+	CreatedAt       time.Time  `json:"created_at"`
+	MergedAt        *time.Time `json:"merged_at"`
+	ClosedAt        *time.Time `json:"closed_at"`
+	SHA             string     `json:"sha"`
+	MergeCommitSHA  string     `json:"merge_commit_sha"`
+	SquashCommitSHA string     `json:"squash_commit_sha"`
+	WebURL          string     `json:"web_url"`
+
+	// 以下字段由统计流程填充
+	ProjectID string `json:"-"`
+	// 合并请求的代码量，根据差异内容统计
+	Additions int `json:"-"`
+	Deletions int `json:"-"`
+	// 提交版本数，每次推送新提交产生一个版本
+	Revisions int `json:"-"`
+	// 按时间排序的评论和系统记录
+	Notes []Note `json:"-"`
+	// 批准合并请求的用户
+	ApprovedBy []User `json:"-"`
+}
+
+// MergeRequestStats 合并请求的汇总统计
+type MergeRequestStats struct {
+	// 统计范围内创建、合并、关闭的合并请求数
+	Opened int
+	Merged int
+	Closed int
+	// 统计范围内合并的合并请求的代码量和版本数
+	Additions int
+	Deletions int
+	Revisions int
+	// 统计范围内创建的合并请求从创建到首次评审的时长
+	TimeToFirstReview []time.Duration
+	// 统计范围内合并的合并请求从创建到合并的时长
+	TimeToMerge []time.Duration
+}
+
+// MergeRequestReport 按用户和项目汇总的合并请求统计
+type MergeRequestReport struct {
+	Users map[string]MergeRequestStats
+	// 用户在各项目中的统计，键为 [用户, 项目 ID]
+	UserProjects map[[2]string]MergeRequestStats
+	Projects     map[string]MergeRequestStats
+}
+
+// AuthorName 合并请求作者的名称，与提交作者名称对应
+func (mr MergeRequest) AuthorName() string {
+	return mr.Author.DisplayName()
+}
+
+// IsApproval 判断是否为批准合并请求的系统记录
+func (n Note) IsApproval() bool {
+	return n.System && strings.HasPrefix(n.Body, "approved this merge request")
+}
+
+// FirstReviewAt 返回作者以外的用户首次评论或批准的时间，没有评审时返回 nil
+func (mr MergeRequest) FirstReviewAt() *time.Time {
+	for _, note := range mr.Notes {
+		if note.Author.Username == mr.Author.Username {
+			continue
+		}
+		if note.System && !note.IsApproval() {
+			continue
+		}
+		at := note.CreatedAt
+		return &at
+	}
+	return nil
+}
+
+// GetProjectMergeRequests 获取项目在时间范围内创建、合并或关闭的合并请求，
+// 并补充评论、代码量和版本数
+func (c *GitLabClient) GetProjectMergeRequests(projectID, startDate, endDate string) ([]MergeRequest, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 在统计范围内发生过变化的合并请求，更新时间一定不早于开始时间
+	since, _ := dateRangeParams(startDate, endDate)
+	path := fmt.Sprintf("/projects/%s/merge_requests", projectID)
+	all, err := getAllPages[MergeRequest](c, path, map[string]string{
+		"scope":         "all",
+		"state":         "all",
+		"updated_after": since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var mrs []MergeRequest
+	for _, mr := range all {
+		if inWindow(mr.CreatedAt, start, end) || (mr.MergedAt != nil && inWindow(*mr.MergedAt, start, end)) ||
+			(mr.ClosedAt != nil && inWindow(*mr.ClosedAt, start, end)) {
+			mr.ProjectID = projectID
+			mrs = append(mrs, mr)
+		}
+	}
+
+	// 并发获取每个合并请求的详情，任一合并请求获取失败时整个项目视为失败，
+	// 避免缺少评论、版本和差异的合并请求拉低评审时长和代码量
+	errs := make([]error, len(mrs))
+	var wg sync.WaitGroup
+	work := make(chan int)
+	workerCount := 5
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				if err := c.loadMergeRequestDetails(&mrs[index]); err != nil {
+					errs[index] = fmt.Errorf("获取合并请求 !%d 详情失败: %v", mrs[index].IID, err)
+				}
+			}
+		}()
+	}
+	for i := range mrs {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(mrs, func(i, j int) bool {
+		return mrs[i].CreatedAt.Before(mrs[j].CreatedAt)
+	})
+	return mrs, nil
+}
+
+// loadMergeRequestDetails 获取合并请求的评论、批准人、代码量和版本数
+func (c *GitLabClient) loadMergeRequestDetails(mr *MergeRequest) error {
+	basePath := fmt.Sprintf("/projects/%s/merge_requests/%d", mr.ProjectID, mr.IID)
+
+	notes, err := getAllPages[Note](c, basePath+"/notes", map[string]string{"sort": "asc", "order_by": "created_at"})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].CreatedAt.Before(notes[j].CreatedAt)
+	})
+	mr.Notes = notes
+
+	versions, err := getAllPages[json.RawMessage](c, basePath+"/versions", nil)
+	if err != nil {
+		return err
+	}
+	mr.Revisions = len(versions)
+
+	// 批准接口在部分 GitLab 版本中不可用，只请求一次，失败时只依据系统记录判断批准
+	if body, err := c.doRequest("GET", basePath+"/approvals", nil); err == nil {
+		var approvals struct {
+			ApprovedBy []struct {
+				User User `json:"user"`
+			} `json:"approved_by"`
+		}
+		if err := json.Unmarshal(body, &approvals); err == nil {
+			for _, approval := range approvals.ApprovedBy {
+				mr.ApprovedBy = append(mr.ApprovedBy, approval.User)
+			}
+		}
+	}
+
+	// /changes 接口在文件较多时会截断，分页的 /diffs 接口可以取得全部差异
+	diffs, err := getAllPages[struct {
+		Diff string `json:"diff"`
+	}](c, basePath+"/diffs", nil)
+	if err != nil {
+		return err
+	}
+	for _, diff := range diffs {
+		additions, deletions := countDiffLines(diff.Diff)
+		mr.Additions += additions
+		mr.Deletions += deletions
+	}
+	return nil
+}
+
+// countDiffLines 统计差异内容中增加和删除的行数。GitLab 返回的差异不含 ---/+++ 文件头，
+// 以 -- 或 ++ 开头的内容行也需要计入
+func countDiffLines(diff string) (int, int) {
+	var additions, deletions int
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+// dateWindow 将包含首尾的日期范围转换为 [start, end) 时间区间
+func dateWindow(startDate, endDate string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始日期格式无效: %s", startDate)
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("结束日期格式无效: %s", endDate)
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// inWindow 判断时间是否在 [start, end) 区间内
+func inWindow(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+// SummarizeMergeRequests 按作者和项目汇总统计范围内的合并请求
+func SummarizeMergeRequests(mrs []MergeRequest, startDate, endDate string) (MergeRequestReport, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return MergeRequestReport{}, err
+	}
+
+	report := MergeRequestReport{
+		Users:        make(map[string]MergeRequestStats),
+		UserProjects: make(map[[2]string]MergeRequestStats),
+		Projects:     make(map[string]MergeRequestStats),
+	}
+	for _, mr := range mrs {
+		user := mr.AuthorName()
+		userProject := [2]string{user, mr.ProjectID}
+		report.Users[user] = addMergeRequest(report.Users[user], mr, start, end)
+		report.UserProjects[userProject] = addMergeRequest(report.UserProjects[userProject], mr, start, end)
+		report.Projects[mr.ProjectID] = addMergeRequest(report.Projects[mr.ProjectID], mr, start, end)
+	}
+	return report, nil
+}
+
+// addMergeRequest 将合并请求计入汇总
+func addMergeRequest(s MergeRequestStats, mr MergeRequest, start, end time.Time) MergeRequestStats {
+	if inWindow(mr.CreatedAt, start, end) {
+		s.Opened++
+		if reviewAt := mr.FirstReviewAt(); reviewAt != nil {
+			s.TimeToFirstReview = append(s.TimeToFirstReview, reviewAt.Sub(mr.CreatedAt))
+		}
+	}
+	if mr.MergedAt != nil && inWindow(*mr.MergedAt, start, end) {
+		s.Merged++
+		s.Additions += mr.Additions
+		s.Deletions += mr.Deletions
+		s.Revisions += mr.Revisions
+		s.TimeToMerge = append(s.TimeToMerge, mr.MergedAt.Sub(mr.CreatedAt))
+	}
+	if mr.State == MergeRequestClosed && mr.ClosedAt != nil && inWindow(*mr.ClosedAt, start, end) {
+		s.Closed++
+	}
+	return s
+}
+
+// FilterMergeRequestsByAuthors 只保留目标用户创建的合并请求，目标用户为空时返回全部
+func FilterMergeRequestsByAuthors(mrs []MergeRequest, targetUsers []string) []MergeRequest {
+	if len(targetUsers) == 0 {
+		return mrs
+	}
+
+	targetUsersMap := make(map[string]bool)
+	for _, user := range targetUsers {
+		targetUsersMap[user] = true
+	}
+
+	var filtered []MergeRequest
+	for _, mr := range mrs {
+		if targetUsersMap[mr.AuthorName()] || targetUsersMap[mr.Author.Username] {
+			filtered = append(filtered, mr)
+		}
+	}
+	return filtered
+}
+
+// MedianDuration 返回时长的中位数，没有数据时返回 0
+func MedianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// AverageDuration 返回时长的平均值，没有数据时返回 0
+func AverageDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return total / time.Duration(len(durations))
+}
@@ -0,0 +1,169 @@
+package gitlab
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// 异常提交的处理方式
+const (
+	OutlierOff      = "off"
+	OutlierFlag     = "flag"
+	OutlierCap      = "cap"
+	OutlierExclude  = "exclude"
+	OutlierOverride = "override"
+)
+
+// OutlierPolicy 异常提交的检测和处理策略
+type OutlierPolicy struct {
+	// Action 处理方式：flag 仅标记、cap 截断到阈值、exclude 排除
+	Action string
+	// MaxLines 单个提交的绝对行数阈值（增加+删除），0 表示不启用
+	MaxLines int
+	// Percentile 和 Factor 组成统计规则：超过项目内该百分位行数的 Factor 倍即为异常，Factor 为 0 表示不启用
+	Percentile float64
+	Factor     float64
+	// MinSamples 项目提交数少于该值时不使用统计规则
+	MinSamples int
+	// Allow 人工确认无需处理的提交 SHA（支持前缀）
+	Allow []string
+}
+
+// OutlierCommit 被检测为异常的提交
+type OutlierCommit struct {
+	Commit
+	Lines     int
+	Threshold int
+	Rule      string
+	// Action 实际采取的处理方式，人工放行时为 override
+	Action string
+}
+
+// ValidateOutlierAction 校验异常提交处理方式
+func ValidateOutlierAction(action string) error {
+	switch action {
+	case OutlierOff, OutlierFlag, OutlierCap, OutlierExclude:
+		return nil
+	}
+	return fmt.Errorf("无效的异常提交处理方式: %s，可选值为 off、flag、cap、exclude", action)
+}
+
+// Enabled 判断策略是否启用
+func (p OutlierPolicy) Enabled() bool {
+	return p.Action != "" && p.Action != OutlierOff && (p.MaxLines > 0 || p.Factor > 0)
+}
+
+// allowed 判断提交是否被人工放行
+func (p OutlierPolicy) allowed(sha string) bool {
+	for _, allow := range p.Allow {
+		if allow != "" && strings.HasPrefix(sha, allow) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 按项目检测异常提交并应用处理策略，返回处理后的提交和异常提交列表
+func (p OutlierPolicy) Apply(commits []Commit) ([]Commit, []OutlierCommit) {
+	if !p.Enabled() {
+		return commits, nil
+	}
+
+	// 每个项目提交行数的有序列表，用于计算统计阈值
+	projectLines := make(map[string][]int)
+	for _, commit := range commits {
+		projectLines[commit.ProjectID] = append(projectLines[commit.ProjectID], commitLines(commit))
+	}
+	for _, lines := range projectLines {
+		sort.Ints(lines)
+	}
+
+	var result []Commit
+	var outliers []OutlierCommit
+	for _, commit := range commits {
+		lines := commitLines(commit)
+		threshold, rule := p.threshold(projectLines[commit.ProjectID], lines)
+		if threshold <= 0 || lines <= threshold {
+			result = append(result, commit)
+			continue
+		}
+
+		outlier := OutlierCommit{
+			Commit:    commit,
+			Lines:     lines,
+			Threshold: threshold,
+			Rule:      rule,
+			Action:    p.Action,
+		}
+
+		switch {
+		case p.allowed(commit.ID):
+			outlier.Action = OutlierOverride
+			result = append(result, commit)
+		case p.Action == OutlierCap:
+			result = append(result, capCommit(commit, threshold))
+		case p.Action == OutlierExclude:
+			// 排除的提交不再计入统计
+		default:
+			result = append(result, commit)
+		}
+		outliers = append(outliers, outlier)
+	}
+
+	sort.SliceStable(outliers, func(i, j int) bool {
+		return outliers[i].Lines > outliers[j].Lines
+	})
+	return result, outliers
+}
+
+// threshold 计算提交的异常阈值，取启用规则中较小的一个。sorted 为项目内所有提交的行数（升序），
+// 统计规则只使用其余提交的行数，避免被检测的提交本身抬高百分位
+func (p OutlierPolicy) threshold(sorted []int, lines int) (int, string) {
+	threshold, rule := p.MaxLines, fmt.Sprintf("超过绝对阈值 %d 行", p.MaxLines)
+
+	if p.Factor > 0 && len(sorted) >= p.MinSamples && len(sorted) > 1 {
+		value := percentileExcluding(sorted, lines, p.Percentile)
+		statThreshold := int(math.Ceil(float64(value) * p.Factor))
+		if statThreshold > 0 && (threshold <= 0 || statThreshold < threshold) {
+			threshold = statThreshold
+			rule = fmt.Sprintf("超过 P%g(%d 行) × %g", p.Percentile, value, p.Factor)
+		}
+	}
+	return threshold, rule
+}
+
+// percentileExcluding 使用最近秩法计算去掉一个 value 后的百分位数，sorted 为升序且包含 value，长度至少为 2
+func percentileExcluding(sorted []int, value int, p float64) int {
+	n := len(sorted) - 1
+	rank := int(math.Ceil(p / 100 * float64(n)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > n {
+		rank = n
+	}
+	// 去掉的值位于 pos，之后的元素在剩余列表中前移一位
+	if pos := sort.SearchInts(sorted, value); rank-1 < pos {
+		return sorted[rank-1]
+	}
+	return sorted[rank]
+}
+
+// commitLines 提交的总行数（增加+删除）
+func commitLines(commit Commit) int {
+	return commit.Stats.Additions + commit.Stats.Deletions
+}
+
+// capCommit 将提交的行数按比例截断到阈值
+func capCommit(commit Commit, threshold int) Commit {
+	lines := commitLines(commit)
+	additions := int(math.Round(float64(commit.Stats.Additions) * float64(threshold) / float64(lines)))
+	commit.Stats = CommitStats{
+		Additions: additions,
+		Deletions: threshold - additions,
+		Total:     threshold,
+	}
+	return commit
+}
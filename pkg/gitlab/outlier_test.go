@@ -0,0 +1,155 @@
+package gitlab
+
+import (
+	"fmt"
+	"testing"
+)
+
+// seq 返回 1..n
+func seq(n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i + 1
+	}
+	return values
+}
+
+func TestPercentileExcluding(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []int
+		value  int
+		p      float64
+		want   int
+	}{
+		{"去掉最大值", []int{1, 2, 3, 4, 5}, 5, 99, 4},
+		{"去掉最小值", []int{1, 2, 3, 4, 5}, 1, 99, 5},
+		{"去掉的值在秩之后", []int{1, 2, 3, 4, 5}, 3, 50, 2},
+		{"去掉的值在秩之前", []int{1, 2, 3, 4, 5}, 3, 75, 4},
+		{"重复值只去掉一个", []int{1, 5, 5, 5}, 5, 99, 5},
+		{"P0 取其余提交的最小值", []int{1, 2, 3}, 1, 0, 2},
+		{"只有两个提交", []int{10, 200000}, 200000, 99, 10},
+		{"20 个值的 P99", seq(20), 20, 99, 19},
+		{"101 个值的 P99", seq(101), 101, 99, 99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentileExcluding(tt.sorted, tt.value, tt.p); got != tt.want {
+				t.Errorf("percentileExcluding(%v, %d, %g) = %d, want %d", tt.sorted, tt.value, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutlierPolicyThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    OutlierPolicy
+		sorted    []int
+		lines     int
+		threshold int
+		rule      string
+	}{
+		{
+			name:      "样本数少于下限时只使用绝对阈值",
+			policy:    OutlierPolicy{MaxLines: 5000, Percentile: 99, Factor: 3, MinSamples: 20},
+			sorted:    seq(19),
+			lines:     19,
+			threshold: 5000,
+			rule:      "超过绝对阈值 5000 行",
+		},
+		{
+			name:      "样本数等于下限时使用其余提交的百分位",
+			policy:    OutlierPolicy{MaxLines: 5000, Percentile: 99, Factor: 3, MinSamples: 20},
+			sorted:    seq(20),
+			lines:     20,
+			threshold: 57,
+			rule:      "超过 P99(19 行) × 3",
+		},
+		{
+			name:      "统计阈值高于绝对阈值时取绝对阈值",
+			policy:    OutlierPolicy{MaxLines: 50, Percentile: 99, Factor: 3, MinSamples: 20},
+			sorted:    seq(20),
+			lines:     20,
+			threshold: 50,
+			rule:      "超过绝对阈值 50 行",
+		},
+		{
+			name:      "统计阈值等于绝对阈值时取绝对阈值",
+			policy:    OutlierPolicy{MaxLines: 57, Percentile: 99, Factor: 3, MinSamples: 20},
+			sorted:    seq(20),
+			lines:     20,
+			threshold: 57,
+			rule:      "超过绝对阈值 57 行",
+		},
+		{
+			name:      "未启用绝对阈值时使用统计阈值",
+			policy:    OutlierPolicy{Percentile: 95, Factor: 2, MinSamples: 20},
+			sorted:    seq(20),
+			lines:     1,
+			threshold: 40,
+			rule:      "超过 P95(20 行) × 2",
+		},
+		{
+			name:      "统计阈值向上取整",
+			policy:    OutlierPolicy{Percentile: 100, Factor: 1.5, MinSamples: 1},
+			sorted:    []int{7, 9},
+			lines:     9,
+			threshold: 11,
+			rule:      "超过 P100(7 行) × 1.5",
+		},
+		{
+			name:      "统计阈值为 0 时不使用统计规则",
+			policy:    OutlierPolicy{MaxLines: 100, Percentile: 99, Factor: 3, MinSamples: 1},
+			sorted:    []int{0, 0, 0},
+			lines:     0,
+			threshold: 100,
+			rule:      "超过绝对阈值 100 行",
+		},
+		{
+			name:      "倍数为 0 时不使用统计规则",
+			policy:    OutlierPolicy{MaxLines: 5000, Percentile: 99, MinSamples: 1},
+			sorted:    seq(20),
+			lines:     20,
+			threshold: 5000,
+			rule:      "超过绝对阈值 5000 行",
+		},
+		{
+			name:      "项目只有一个提交时只使用绝对阈值",
+			policy:    OutlierPolicy{MaxLines: 5000, Percentile: 99, Factor: 3},
+			sorted:    []int{7},
+			lines:     7,
+			threshold: 5000,
+			rule:      "超过绝对阈值 5000 行",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threshold, rule := tt.policy.threshold(tt.sorted, tt.lines)
+			if threshold != tt.threshold || rule != tt.rule {
+				t.Errorf("threshold(%v, %d) = (%d, %q), want (%d, %q)", tt.sorted, tt.lines, threshold, rule, tt.threshold, tt.rule)
+			}
+		})
+	}
+}
+
+func TestOutlierPolicyApplyFlagsLargeCommit(t *testing.T) {
+	var commits []Commit
+	for i := 0; i < 30; i++ {
+		commits = append(commits, Commit{ID: fmt.Sprintf("c%02d", i), ProjectID: "1", Stats: CommitStats{Additions: 10 + i}})
+	}
+	commits = append(commits, Commit{ID: "sdk", ProjectID: "1", Stats: CommitStats{Additions: 200000}})
+
+	policy := OutlierPolicy{Action: OutlierFlag, Percentile: 99, Factor: 3, MinSamples: 20}
+	result, outliers := policy.Apply(commits)
+	if len(result) != len(commits) {
+		t.Errorf("flag 不应移除提交: 剩余 %d 个，want %d", len(result), len(commits))
+	}
+	if len(outliers) != 1 {
+		t.Fatalf("outliers = %+v, want 1 个", outliers)
+	}
+	got := outliers[0]
+	if got.ID != "sdk" || got.Threshold != 117 || got.Rule != "超过 P99(39 行) × 3" {
+		t.Errorf("outlier = (%s, %d, %q), want (sdk, 117, %q)", got.ID, got.Threshold, got.Rule, "超过 P99(39 行) × 3")
+	}
+}
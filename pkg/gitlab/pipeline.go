@@ -0,0 +1,189 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 流水线状态
+const (
+	PipelineSuccess  = "success"
+	PipelineFailed   = "failed"
+	PipelineCanceled = "canceled"
+	PipelineSkipped  = "skipped"
+)
+
+// Pipeline CI 流水线信息
+type Pipeline struct {
+	ID         int        `json:"id"`
+	IID        int        `json:"iid"`
+	Status     string     `json:"status"`
+	Source     string     `json:"source"`
+	Ref        string     `json:"ref"`
+	SHA        string     `json:"sha"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	// Duration 运行时长（秒），仅在流水线详情中返回
+	Duration float64 `json:"duration"`
+	// User 触发流水线的用户，仅在流水线详情中返回
+	User   User   `json:"user"`
+	WebURL string `json:"web_url"`
+
+	// 流水线所属的项目 ID，由统计流程填充
+	ProjectID string `json:"-"`
+}
+
+// PipelineStats 流水线的汇总统计
+type PipelineStats struct {
+	Total    int
+	Success  int
+	Failed   int
+	Canceled int
+	Skipped  int
+	// 已完成的流水线的运行时长
+	Durations []time.Duration
+}
+
+// PipelineReport 按项目和触发用户汇总的流水线统计
+type PipelineReport struct {
+	Projects map[string]PipelineStats
+	Users    map[string]PipelineStats
+	// 用户在各项目中的统计，键为 [用户, 项目 ID]
+	UserProjects map[[2]string]PipelineStats
+}
+
+// SuccessRate 成功率（%），按成功和失败的流水线计算，取消和跳过的不计入
+func (s PipelineStats) SuccessRate() float64 {
+	if s.Success+s.Failed == 0 {
+		return 0
+	}
+	return float64(s.Success) / float64(s.Success+s.Failed) * 100
+}
+
+// FailureRate 失败率（%），按成功和失败的流水线计算
+func (s PipelineStats) FailureRate() float64 {
+	if s.Success+s.Failed == 0 {
+		return 0
+	}
+	return float64(s.Failed) / float64(s.Success+s.Failed) * 100
+}
+
+// GetProjectPipelines 获取项目在时间范围内创建的流水线及其详情
+func (c *GitLabClient) GetProjectPipelines(projectID, startDate, endDate string) ([]Pipeline, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 流水线接口只支持按更新时间过滤，创建时间在统计范围内的流水线更新时间一定不早于开始时间
+	since, _ := dateRangeParams(startDate, endDate)
+	all, err := getAllPages[Pipeline](c, fmt.Sprintf("/projects/%s/pipelines", projectID), map[string]string{
+		"updated_after": since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pipelines []Pipeline
+	for _, pipeline := range all {
+		if inWindow(pipeline.CreatedAt, start, end) {
+			pipeline.ProjectID = projectID
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+
+	// 并发获取流水线详情，补充运行时长和触发用户。任一流水线获取失败时整个项目视为失败，
+	// 避免失败的流水线不归属任何用户、运行时长被低估
+	errs := make([]error, len(pipelines))
+	var wg sync.WaitGroup
+	work := make(chan int)
+	workerCount := 5
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				p := &pipelines[index]
+				body, err := c.getWithRetry(fmt.Sprintf("/projects/%s/pipelines/%d", projectID, p.ID), nil)
+				if err != nil {
+					errs[index] = fmt.Errorf("获取流水线 %d 详情失败: %v", p.ID, err)
+					continue
+				}
+				var detail Pipeline
+				if err := json.Unmarshal(body, &detail); err != nil {
+					errs[index] = fmt.Errorf("解析流水线 %d 详情失败: %v", p.ID, err)
+					continue
+				}
+				p.Duration = detail.Duration
+				p.User = detail.User
+				p.StartedAt = detail.StartedAt
+				p.FinishedAt = detail.FinishedAt
+			}
+		}()
+	}
+	for i := range pipelines {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		return pipelines[i].CreatedAt.Before(pipelines[j].CreatedAt)
+	})
+	return pipelines, nil
+}
+
+// SummarizePipelines 按项目和触发用户汇总流水线，项目统计包含全部流水线，
+// 用户统计只包含目标用户触发的流水线，目标用户为空时包含全部用户
+func SummarizePipelines(pipelines []Pipeline, targetUsers []string) PipelineReport {
+	targetUsersMap := make(map[string]bool)
+	for _, user := range targetUsers {
+		targetUsersMap[user] = true
+	}
+
+	report := PipelineReport{
+		Projects:     make(map[string]PipelineStats),
+		Users:        make(map[string]PipelineStats),
+		UserProjects: make(map[[2]string]PipelineStats),
+	}
+	for _, pipeline := range pipelines {
+		report.Projects[pipeline.ProjectID] = addPipeline(report.Projects[pipeline.ProjectID], pipeline)
+
+		user := pipeline.User.DisplayName()
+		if len(targetUsersMap) > 0 && !targetUsersMap[user] && !targetUsersMap[pipeline.User.Username] {
+			continue
+		}
+		userProject := [2]string{user, pipeline.ProjectID}
+		report.Users[user] = addPipeline(report.Users[user], pipeline)
+		report.UserProjects[userProject] = addPipeline(report.UserProjects[userProject], pipeline)
+	}
+	return report
+}
+
+// addPipeline 将流水线计入汇总
+func addPipeline(s PipelineStats, pipeline Pipeline) PipelineStats {
+	s.Total++
+	switch pipeline.Status {
+	case PipelineSuccess:
+		s.Success++
+	case PipelineFailed:
+		s.Failed++
+	case PipelineCanceled:
+		s.Canceled++
+	case PipelineSkipped:
+		s.Skipped++
+	}
+	if pipeline.Duration > 0 && pipeline.FinishedAt != nil {
+		s.Durations = append(s.Durations, time.Duration(pipeline.Duration*float64(time.Second)))
+	}
+	return s
+}
@@ -0,0 +1,168 @@
+package gitlab
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 贡献统计方式
+const (
+	// ContributionGross 总贡献：回滚提交和被回滚的提交都照常计入
+	ContributionGross = "gross"
+	// ContributionNet 净贡献：回滚提交和统计范围内被回滚的原提交都不计入
+	ContributionNet = "net"
+)
+
+// 回滚提交的识别方式
+const (
+	RevertBySHA   = "sha"
+	RevertByStats = "stats"
+)
+
+var (
+	// git revert 生成的提交信息
+	revertSHAPattern = regexp.MustCompile(`This reverts commit ([0-9a-fA-F]{7,40})`)
+	// git revert 和 GitLab 回滚按钮生成的标题：Revert "原标题"
+	revertTitlePattern = regexp.MustCompile(`^Revert "(.+)"$`)
+)
+
+// RevertPair 回滚提交及其回滚的原提交
+type RevertPair struct {
+	Revert Commit
+	// Original 被回滚的原提交，原提交不在统计范围内时为 nil
+	Original *Commit
+	// OriginalID 原提交 SHA，仅通过提交信息识别时可能不在统计范围内
+	OriginalID string
+	Method     string
+}
+
+// ValidateContributionMode 校验贡献统计方式
+func ValidateContributionMode(mode string) error {
+	if mode == ContributionGross || mode == ContributionNet {
+		return nil
+	}
+	return fmt.Errorf("无效的贡献统计方式: %s，可选值为 gross、net", mode)
+}
+
+// DetectReverts 识别回滚提交
+//
+// 优先通过提交信息中的 "This reverts commit <sha>" 识别原提交；
+// 否则对于标题为 Revert "<原标题>" 的提交，在同一项目中查找标题相同、
+// 增加和删除行数正好相反的较早提交。
+func DetectReverts(commits []Commit) []RevertPair {
+	// 按项目建立索引
+	byID := make(map[string]map[string]int)
+	for i, commit := range commits {
+		if byID[commit.ProjectID] == nil {
+			byID[commit.ProjectID] = make(map[string]int)
+		}
+		byID[commit.ProjectID][commit.ID] = i
+	}
+
+	var pairs []RevertPair
+	matched := make(map[int]bool)
+	for i, revert := range commits {
+		if m := revertSHAPattern.FindStringSubmatch(revert.Message); m != nil {
+			pair := RevertPair{Revert: revert, OriginalID: strings.ToLower(m[1]), Method: RevertBySHA}
+			if j, ok := findCommitByPrefix(commits, byID[revert.ProjectID], pair.OriginalID); ok && j != i {
+				original := commits[j]
+				pair.Original = &original
+				pair.OriginalID = original.ID
+				matched[j] = true
+			}
+			pairs = append(pairs, pair)
+			continue
+		}
+
+		m := revertTitlePattern.FindStringSubmatch(revert.Subject())
+		if m == nil {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			original := commits[j]
+			if matched[j] || original.ProjectID != revert.ProjectID || original.Subject() != m[1] {
+				continue
+			}
+			if original.Stats.Additions == revert.Stats.Deletions && original.Stats.Deletions == revert.Stats.Additions {
+				matched[j] = true
+				pairs = append(pairs, RevertPair{Revert: revert, Original: &original, OriginalID: original.ID, Method: RevertByStats})
+				break
+			}
+		}
+	}
+	return pairs
+}
+
+// findCommitByPrefix 按完整 SHA 或前缀查找提交
+func findCommitByPrefix(commits []Commit, index map[string]int, sha string) (int, bool) {
+	if i, ok := index[sha]; ok {
+		return i, true
+	}
+	for i, commit := range commits {
+		if _, inProject := index[commit.ID]; inProject && strings.HasPrefix(commit.ID, sha) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ApplyContributionMode 按贡献统计方式处理回滚提交，净贡献模式下移除回滚提交和被回滚的原提交
+func ApplyContributionMode(commits []Commit, pairs []RevertPair, mode string) []Commit {
+	if mode != ContributionNet || len(pairs) == 0 {
+		return commits
+	}
+
+	removed := make(map[string]bool)
+	for _, pair := range pairs {
+		removed[pair.Revert.ProjectID+"/"+pair.Revert.ID] = true
+		if pair.Original != nil {
+			removed[pair.Original.ProjectID+"/"+pair.Original.ID] = true
+		}
+	}
+
+	var result []Commit
+	for _, commit := range commits {
+		if !removed[commit.ProjectID+"/"+commit.ID] {
+			result = append(result, commit)
+		}
+	}
+	return result
+}
+
+// AddRevertedStats 将被回滚的代码量计入原提交作者的统计，
+// 目标用户不为空时只统计目标用户，被排除规则过滤掉的作者不计入
+func AddRevertedStats(stats map[string]UserStats, pairs []RevertPair, targetUsers []string, excluded []ExcludedAuthor) {
+	targetUsersMap := make(map[string]bool)
+	for _, user := range targetUsers {
+		targetUsersMap[user] = true
+	}
+	excludedMap := make(map[string]bool)
+	for _, author := range excluded {
+		excludedMap[author.Author] = true
+	}
+
+	for _, pair := range pairs {
+		if pair.Original == nil {
+			continue
+		}
+		original := pair.Original
+		if len(targetUsersMap) > 0 && !targetUsersMap[original.AuthorName] {
+			continue
+		}
+		if excludedMap[original.AuthorName] {
+			continue
+		}
+
+		userStats, exists := stats[original.AuthorName]
+		if !exists {
+			userStats = UserStats{Projects: make(map[string]ProjectStats)}
+		}
+		lines := commitLines(*original)
+		userStats.Reverted += lines
+		projectStats := userStats.Projects[original.ProjectID]
+		projectStats.Reverted += lines
+		userStats.Projects[original.ProjectID] = projectStats
+		stats[original.AuthorName] = userStats
+	}
+}
@@ -0,0 +1,153 @@
+package gitlab
+
+import "sort"
+
+// ReviewStats 用户参与代码评审的统计
+type ReviewStats struct {
+	// 评审过的他人合并请求数（评论或批准）
+	Reviews int
+	// 在他人合并请求上的评论数
+	Comments int
+	// 批准的合并请求数
+	Approvals int
+	// 在自己没有贡献的项目中评审的合并请求数
+	CrossProjectReviews int
+	// 评审过的项目
+	Projects []string
+}
+
+// ReviewReport 代码评审参与情况
+type ReviewReport struct {
+	Users map[string]ReviewStats
+	// 评审人和作者之间评审的合并请求数，键为 [评审人, 作者]
+	Matrix map[[2]string]int
+}
+
+// ContributedProjects 返回每个用户有提交或创建过合并请求的项目，用于判断跨项目评审
+func ContributedProjects(commits []Commit, mrs []MergeRequest) map[string]map[string]bool {
+	projects := make(map[string]map[string]bool)
+	add := func(user, projectID string) {
+		if projects[user] == nil {
+			projects[user] = make(map[string]bool)
+		}
+		projects[user][projectID] = true
+	}
+	for _, commit := range commits {
+		add(commit.AuthorName, commit.ProjectID)
+	}
+	for _, mr := range mrs {
+		add(mr.AuthorName(), mr.ProjectID)
+	}
+	return projects
+}
+
+// SummarizeReviews 统计每个用户在统计范围内对他人合并请求的评论和批准。
+// include 判断评审人是否计入统计，为 nil 时统计全部评审人；
+// ownProjects 为每个用户有贡献的项目，不在其中的评审计为跨项目评审
+func SummarizeReviews(mrs []MergeRequest, startDate, endDate string, include func(User) bool, ownProjects map[string]map[string]bool) (ReviewReport, error) {
+	start, end, err := dateWindow(startDate, endDate)
+	if err != nil {
+		return ReviewReport{}, err
+	}
+
+	report := ReviewReport{
+		Users:  make(map[string]ReviewStats),
+		Matrix: make(map[[2]string]int),
+	}
+	projects := make(map[string]map[string]bool)
+
+	for _, mr := range mrs {
+		author := mr.AuthorName()
+		comments := make(map[string]int)
+		approved := make(map[string]bool)
+		reviewers := make(map[string]User)
+
+		for _, note := range mr.Notes {
+			if note.Author.Username == mr.Author.Username || !inWindow(note.CreatedAt, start, end) {
+				continue
+			}
+			name := note.Author.DisplayName()
+			switch {
+			case note.IsApproval():
+				approved[name] = true
+			case note.System:
+				continue
+			default:
+				comments[name]++
+			}
+			reviewers[name] = note.Author
+		}
+
+		// 批准接口返回的批准人没有对应的系统记录时，按合并请求所在的统计范围计入
+		for _, user := range mr.ApprovedBy {
+			name := user.DisplayName()
+			if user.Username == mr.Author.Username || approved[name] || hasApprovalNote(mr, user) {
+				continue
+			}
+			approved[name] = true
+			reviewers[name] = user
+		}
+
+		for name, user := range reviewers {
+			if include != nil && !include(user) {
+				continue
+			}
+			stats := report.Users[name]
+			stats.Reviews++
+			stats.Comments += comments[name]
+			if approved[name] {
+				stats.Approvals++
+			}
+			if !ownProjects[name][mr.ProjectID] {
+				stats.CrossProjectReviews++
+			}
+			report.Users[name] = stats
+			report.Matrix[[2]string{name, author}]++
+
+			if projects[name] == nil {
+				projects[name] = make(map[string]bool)
+			}
+			projects[name][mr.ProjectID] = true
+		}
+	}
+
+	for name, stats := range report.Users {
+		for projectID := range projects[name] {
+			stats.Projects = append(stats.Projects, projectID)
+		}
+		sort.Strings(stats.Projects)
+		report.Users[name] = stats
+	}
+	return report, nil
+}
+
+// hasApprovalNote 判断用户是否有批准合并请求的系统记录
+func hasApprovalNote(mr MergeRequest, user User) bool {
+	for _, note := range mr.Notes {
+		if note.IsApproval() && note.Author.Username == user.Username {
+			return true
+		}
+	}
+	return false
+}
+
+// ReviewMatrixUsers 返回评审矩阵中的评审人和作者列表
+func (r ReviewReport) ReviewMatrixUsers() ([]string, []string) {
+	reviewerSet := make(map[string]bool)
+	authorSet := make(map[string]bool)
+	for key := range r.Matrix {
+		reviewerSet[key[0]] = true
+		authorSet[key[1]] = true
+	}
+	reviewers := make([]string, 0, len(reviewerSet))
+	for name := range reviewerSet {
+		reviewers = append(reviewers, name)
+	}
+	authors := make([]string, 0, len(authorSet))
+	for name := range authorSet {
+		authors = append(authors, name)
+	}
+	sort.Strings(reviewers)
+	sort.Strings(authors)
+	return reviewers, authors
+}
@@ -0,0 +1,218 @@
+package gitlab
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultTicketPatterns 默认的任务编号规则：GitLab issue（#123、group/project#123）和 Jira 编号（PAY-456）。
+// issue 编号前必须是行首、空白或括号等，避免把 fix#12 识别为编号
+var DefaultTicketPatterns = []string{
+	`(?:^|[^\w/.#-])((?:[\w.-]+/)+[\w.-]+#\d+|#\d+)\b`,
+	`\b[A-Z][A-Z0-9]+-\d+\b`,
+}
+
+// defaultTicketExcludedPrefixes 使用默认规则时不视为任务编号的前缀，如 UTF-8、SHA-256、ISO-8601、HTTP-2
+var defaultTicketExcludedPrefixes = []string{
+	"AES", "CVE", "ECMA", "HTTP", "IEEE", "ISO", "RFC", "RSA", "SHA", "SSL", "TLS", "UTF",
+}
+
+// 任务编号末尾的数字和分隔符，用于提取前缀
+var ticketSuffixPattern = regexp.MustCompile(`[-#]?\d+$`)
+
+// TicketExtractor 从提交信息中提取任务编号
+type TicketExtractor struct {
+	patterns []*regexp.Regexp
+	// 不视为任务编号的前缀，只在使用默认规则时生效
+	excluded map[string]bool
+}
+
+// TicketStats 单个任务编号或编号前缀的汇总
+type TicketStats struct {
+	Key    string
+	Prefix string
+	// ProjectID 不带项目路径的 GitLab issue（#123）只在所属项目内有效，其余编号为空
+	ProjectID string
+	Commits   int
+	Additions int
+	Deletions int
+	Users     []string
+}
+
+// TraceabilityStats 用户在某个项目中提交的可追溯情况
+type TraceabilityStats struct {
+	User       string
+	ProjectID  string
+	Commits    int
+	Referenced int
+	Additions  int
+	Deletions  int
+	// 没有引用任务编号的提交的代码量
+	UnreferencedLines int
+}
+
+// TicketReport 任务编号分析结果
+type TicketReport struct {
+	Tickets      []TicketStats
+	Prefixes     []TicketStats
+	Traceability []TraceabilityStats
+}
+
+// NewTicketExtractor 创建任务编号提取器，patterns 为空时使用默认规则。
+// 正则表达式包含捕获组时以第一个捕获组作为编号，否则使用完整匹配。
+func NewTicketExtractor(patterns []string) (*TicketExtractor, error) {
+	e := &TicketExtractor{}
+	if len(patterns) == 0 {
+		patterns = DefaultTicketPatterns
+		e.excluded = make(map[string]bool)
+		for _, prefix := range defaultTicketExcludedPrefixes {
+			e.excluded[prefix] = true
+		}
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("任务编号规则 %s 无效: %v", pattern, err)
+		}
+		e.patterns = append(e.patterns, re)
+	}
+	return e, nil
+}
+
+// Extract 返回提交信息中引用的任务编号（去重）
+func (e *TicketExtractor) Extract(commit Commit) []string {
+	text := commit.Message
+	if text == "" {
+		text = commit.Title
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, re := range e.patterns {
+		for _, m := range re.FindAllStringSubmatch(text, -1) {
+			key := m[0]
+			if len(m) > 1 && m[1] != "" {
+				key = m[1]
+			}
+			if e.excluded[TicketPrefix(key)] {
+				continue
+			}
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// TicketPrefix 返回任务编号的前缀，如 PAY-456 的前缀为 PAY，#123 的前缀为空
+func TicketPrefix(key string) string {
+	return ticketSuffixPattern.ReplaceAllString(key, "")
+}
+
+// Analyze 按任务编号和编号前缀汇总提交数和代码量，并统计每个用户在各项目中的可追溯比例。
+// 引用多个编号的提交会完整计入每个编号。
+func (e *TicketExtractor) Analyze(commits []Commit) TicketReport {
+	tickets := make(map[[2]string]*TicketStats)
+	prefixes := make(map[string]*TicketStats)
+	traceability := make(map[[2]string]*TraceabilityStats)
+
+	for _, commit := range commits {
+		keys := e.Extract(commit)
+
+		t, exists := traceability[[2]string{commit.AuthorName, commit.ProjectID}]
+		if !exists {
+			t = &TraceabilityStats{User: commit.AuthorName, ProjectID: commit.ProjectID}
+			traceability[[2]string{commit.AuthorName, commit.ProjectID}] = t
+		}
+		t.Commits++
+		t.Additions += commit.Stats.Additions
+		t.Deletions += commit.Stats.Deletions
+		if len(keys) == 0 {
+			t.UnreferencedLines += commitLines(commit)
+			continue
+		}
+		t.Referenced++
+
+		countedPrefixes := make(map[string]bool)
+		for _, key := range keys {
+			prefix := TicketPrefix(key)
+			scope := ""
+			if strings.HasPrefix(key, "#") {
+				scope = commit.ProjectID
+			}
+
+			ticketKey := [2]string{key, scope}
+			if tickets[ticketKey] == nil {
+				tickets[ticketKey] = &TicketStats{Key: key, Prefix: prefix, ProjectID: scope}
+			}
+			addTicketCommit(tickets[ticketKey], commit)
+
+			// 同一提交引用同一前缀的多个编号时，前缀只计一次
+			if prefix == "" || countedPrefixes[prefix] {
+				continue
+			}
+			countedPrefixes[prefix] = true
+			if prefixes[prefix] == nil {
+				prefixes[prefix] = &TicketStats{Key: prefix, Prefix: prefix}
+			}
+			addTicketCommit(prefixes[prefix], commit)
+		}
+	}
+
+	report := TicketReport{}
+	for _, t := range tickets {
+		report.Tickets = append(report.Tickets, *t)
+	}
+	for _, p := range prefixes {
+		report.Prefixes = append(report.Prefixes, *p)
+	}
+	for _, t := range traceability {
+		report.Traceability = append(report.Traceability, *t)
+	}
+
+	sortTicketStats(report.Tickets)
+	sortTicketStats(report.Prefixes)
+	sort.Slice(report.Traceability, func(i, j int) bool {
+		a, b := report.Traceability[i], report.Traceability[j]
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.ProjectID < b.ProjectID
+	})
+	return report
+}
+
+// addTicketCommit 将提交计入任务编号汇总
+func addTicketCommit(t *TicketStats, commit Commit) {
+	t.Commits++
+	t.Additions += commit.Stats.Additions
+	t.Deletions += commit.Stats.Deletions
+	for _, user := range t.Users {
+		if user == commit.AuthorName {
+			return
+		}
+	}
+	t.Users = append(t.Users, commit.AuthorName)
+}
+
+// sortTicketStats 按代码量降序排列
+func sortTicketStats(stats []TicketStats) {
+	for i := range stats {
+		sort.Strings(stats[i].Users)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.ProjectID < b.ProjectID
+	})
+}
@@ -0,0 +1,116 @@
+package gitlab
+
+import (
+	"reflect"
+	"testing"
+)
+
+func ticketCommit(author, projectID, message string, additions, deletions int) Commit {
+	return Commit{
+		AuthorName: author,
+		ProjectID:  projectID,
+		Message:    message,
+		Stats:      CommitStats{Additions: additions, Deletions: deletions, Total: additions + deletions},
+	}
+}
+
+func TestTicketExtractorAnalyze(t *testing.T) {
+	tests := []struct {
+		name         string
+		patterns     []string
+		commits      []Commit
+		tickets      []TicketStats
+		prefixes     []TicketStats
+		traceability []TraceabilityStats
+	}{
+		{
+			name:    "不带项目路径的 issue 按项目分别统计",
+			commits: []Commit{ticketCommit("alice", "1", "修复登录 (#12)", 10, 2), ticketCommit("bob", "2", "#12 调整样式", 3, 1)},
+			tickets: []TicketStats{
+				{Key: "#12", ProjectID: "1", Commits: 1, Additions: 10, Deletions: 2, Users: []string{"alice"}},
+				{Key: "#12", ProjectID: "2", Commits: 1, Additions: 3, Deletions: 1, Users: []string{"bob"}},
+			},
+			traceability: []TraceabilityStats{
+				{User: "alice", ProjectID: "1", Commits: 1, Referenced: 1, Additions: 10, Deletions: 2},
+				{User: "bob", ProjectID: "2", Commits: 1, Referenced: 1, Additions: 3, Deletions: 1},
+			},
+		},
+		{
+			name:    "带项目路径的 issue 跨项目合并统计",
+			commits: []Commit{ticketCommit("alice", "1", "关联 group/sub/app#34", 5, 0), ticketCommit("bob", "2", "见 group/sub/app#34", 1, 1)},
+			tickets: []TicketStats{
+				{Key: "group/sub/app#34", Prefix: "group/sub/app", Commits: 2, Additions: 6, Deletions: 1, Users: []string{"alice", "bob"}},
+			},
+			prefixes: []TicketStats{
+				{Key: "group/sub/app", Prefix: "group/sub/app", Commits: 2, Additions: 6, Deletions: 1, Users: []string{"alice", "bob"}},
+			},
+			traceability: []TraceabilityStats{
+				{User: "alice", ProjectID: "1", Commits: 1, Referenced: 1, Additions: 5},
+				{User: "bob", ProjectID: "2", Commits: 1, Referenced: 1, Additions: 1, Deletions: 1},
+			},
+		},
+		{
+			name:    "紧跟单词的 # 不视为 issue",
+			commits: []Commit{ticketCommit("alice", "1", "fix#12 和 C#7 的问题", 4, 4)},
+			traceability: []TraceabilityStats{
+				{User: "alice", ProjectID: "1", Commits: 1, Additions: 4, Deletions: 4, UnreferencedLines: 8},
+			},
+		},
+		{
+			name:    "Jira 编号按前缀汇总，同一提交的同一前缀只计一次",
+			commits: []Commit{ticketCommit("alice", "1", "PAY-456 PAY-457: 重构支付", 20, 10), ticketCommit("alice", "1", "OPS-1 部署脚本", 2, 0)},
+			tickets: []TicketStats{
+				{Key: "PAY-456", Prefix: "PAY", Commits: 1, Additions: 20, Deletions: 10, Users: []string{"alice"}},
+				{Key: "PAY-457", Prefix: "PAY", Commits: 1, Additions: 20, Deletions: 10, Users: []string{"alice"}},
+				{Key: "OPS-1", Prefix: "OPS", Commits: 1, Additions: 2, Users: []string{"alice"}},
+			},
+			prefixes: []TicketStats{
+				{Key: "PAY", Prefix: "PAY", Commits: 1, Additions: 20, Deletions: 10, Users: []string{"alice"}},
+				{Key: "OPS", Prefix: "OPS", Commits: 1, Additions: 2, Users: []string{"alice"}},
+			},
+			traceability: []TraceabilityStats{
+				{User: "alice", ProjectID: "1", Commits: 2, Referenced: 2, Additions: 22, Deletions: 10},
+			},
+		},
+		{
+			name:    "编码、算法和标准编号不视为 Jira 编号",
+			commits: []Commit{ticketCommit("alice", "1", "改用 UTF-8 编码，SHA-256 校验，ISO-8601 时间，启用 HTTP-2", 6, 0)},
+			traceability: []TraceabilityStats{
+				{User: "alice", ProjectID: "1", Commits: 1, Additions: 6, UnreferencedLines: 6},
+			},
+		},
+		{
+			name:     "自定义规则使用第一个捕获组并且不排除前缀",
+			patterns: []string{`\[(UTF-\d+)\]`},
+			commits:  []Commit{ticketCommit("alice", "1", "[UTF-8] 处理乱码，PAY-1", 3, 3)},
+			tickets: []TicketStats{
+				{Key: "UTF-8", Prefix: "UTF", Commits: 1, Additions: 3, Deletions: 3, Users: []string{"alice"}},
+			},
+			prefixes: []TicketStats{
+				{Key: "UTF", Prefix: "UTF", Commits: 1, Additions: 3, Deletions: 3, Users: []string{"alice"}},
+			},
+			traceability: []TraceabilityStats{
+				{User: "alice", ProjectID: "1", Commits: 1, Referenced: 1, Additions: 3, Deletions: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewTicketExtractor(tt.patterns)
+			if err != nil {
+				t.Fatalf("NewTicketExtractor: %v", err)
+			}
+			got := e.Analyze(tt.commits)
+			if !reflect.DeepEqual(got.Tickets, tt.tickets) {
+				t.Errorf("Tickets = %+v, want %+v", got.Tickets, tt.tickets)
+			}
+			if !reflect.DeepEqual(got.Prefixes, tt.prefixes) {
+				t.Errorf("Prefixes = %+v, want %+v", got.Prefixes, tt.prefixes)
+			}
+			if !reflect.DeepEqual(got.Traceability, tt.traceability) {
+				t.Errorf("Traceability = %+v, want %+v", got.Traceability, tt.traceability)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package period
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 日期格式
+const DateLayout = "2006-01-02"
+
+// Range 统计时间范围，开始和结束日期均包含在内
+type Range struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+
+	// months 按月、季度、半年或年命名的周期的自然长度（月），其余范围为 0
+	months int
+}
+
+var (
+	lastDaysPattern = regexp.MustCompile(`^last-(\d+)([dw])$`)
+	yearPattern     = regexp.MustCompile(`^(\d{4})$`)
+	monthPattern    = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	quarterPattern  = regexp.MustCompile(`^(fy)?(\d{4})-q([1-4])$`)
+	halfPattern     = regexp.MustCompile(`^(fy)?(\d{4})-h([12])$`)
+	fiscalPattern   = regexp.MustCompile(`^fy(\d{4})$`)
+)
+
+// StartDate 返回 YYYY-MM-DD 格式的开始日期
+func (r Range) StartDate() string {
+	return r.Start.Format(DateLayout)
+}
+
+// EndDate 返回 YYYY-MM-DD 格式的结束日期
+func (r Range) EndDate() string {
+	return r.End.Format(DateLayout)
+}
+
+// String 返回周期的可读描述
+func (r Range) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%s 至 %s", r.StartDate(), r.EndDate())
+	}
+	return fmt.Sprintf("%s (%s 至 %s)", r.Name, r.StartDate(), r.EndDate())
+}
+
+// NewRange 根据 YYYY-MM-DD 格式的开始和结束日期创建时间范围
+func NewRange(startDate, endDate string) (Range, error) {
+	start, err := time.ParseInLocation(DateLayout, startDate, time.Local)
+	if err != nil {
+		return Range{}, fmt.Errorf("开始日期格式无效，请使用 YYYY-MM-DD 格式")
+	}
+	end, err := time.ParseInLocation(DateLayout, endDate, time.Local)
+	if err != nil {
+		return Range{}, fmt.Errorf("结束日期格式无效，请使用 YYYY-MM-DD 格式")
+	}
+	if end.Before(start) {
+		return Range{}, fmt.Errorf("结束日期 %s 早于开始日期 %s", endDate, startDate)
+	}
+	return Range{Start: start, End: end}, nil
+}
+
+// Resolve 将周期表达式解析为具体的时间范围
+//
+// 支持的表达式：
+//   - today, yesterday
+//   - last-7d, last-30d, last-2w（截至今天的最近 N 天 / N 周）
+//   - this-week, last-week（周一至周日）
+//   - this-month, last-month, this-quarter, last-quarter, this-year, last-year
+//   - this-fy, last-fy（财年）
+//   - 2026, 2026-09, 2026-Q3, 2026-H1
+//   - FY2026, FY2026-Q1, FY2026-H2（财年从 fiscalStartMonth 开始，FY2026 表示从 2026 年起始月开始的财年）
+//
+// 以 this- 开头的周期统计到今天为止。
+func Resolve(expr string, now time.Time, fiscalStartMonth time.Month) (Range, error) {
+	if fiscalStartMonth < time.January || fiscalStartMonth > time.December {
+		return Range{}, fmt.Errorf("财年起始月份无效: %d", fiscalStartMonth)
+	}
+
+	name := strings.TrimSpace(expr)
+	key := strings.ToLower(name)
+	today := truncateDay(now)
+
+	r, err := resolve(key, today, fiscalStartMonth)
+	if err != nil {
+		return Range{}, err
+	}
+	r.Name = name
+	return r, nil
+}
+
+func resolve(key string, today time.Time, fiscalStartMonth time.Month) (Range, error) {
+	switch key {
+	case "today":
+		return Range{Start: today, End: today}, nil
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+		return Range{Start: yesterday, End: yesterday}, nil
+	case "this-week":
+		return Range{Start: weekStart(today), End: today}, nil
+	case "last-week":
+		start := weekStart(today).AddDate(0, 0, -7)
+		return Range{Start: start, End: start.AddDate(0, 0, 6)}, nil
+	case "this-month":
+		return Range{Start: monthStart(today), End: today, months: 1}, nil
+	case "last-month":
+		start := monthStart(today).AddDate(0, -1, 0)
+		return Range{Start: start, End: start.AddDate(0, 1, -1), months: 1}, nil
+	case "this-quarter":
+		return Range{Start: quarterStart(today), End: today, months: 3}, nil
+	case "last-quarter":
+		start := quarterStart(today).AddDate(0, -3, 0)
+		return Range{Start: start, End: start.AddDate(0, 3, -1), months: 3}, nil
+	case "this-year":
+		return Range{Start: date(today.Year(), time.January, 1), End: today, months: 12}, nil
+	case "last-year":
+		return calendarYear(today.Year() - 1), nil
+	case "this-fy":
+		return Range{Start: fiscalYear(fiscalYearOf(today, fiscalStartMonth), fiscalStartMonth).Start, End: today, months: 12}, nil
+	case "last-fy":
+		return fiscalYear(fiscalYearOf(today, fiscalStartMonth)-1, fiscalStartMonth), nil
+	}
+
+	if m := lastDaysPattern.FindStringSubmatch(key); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		if n <= 0 {
+			return Range{}, fmt.Errorf("无效的周期: %s", key)
+		}
+		return Range{Start: today.AddDate(0, 0, -(n - 1)), End: today}, nil
+	}
+
+	if m := yearPattern.FindStringSubmatch(key); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return calendarYear(year), nil
+	}
+
+	if m := monthPattern.FindStringSubmatch(key); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return Range{}, fmt.Errorf("无效的月份: %s", key)
+		}
+		start := date(year, time.Month(month), 1)
+		return Range{Start: start, End: start.AddDate(0, 1, -1), months: 1}, nil
+	}
+
+	if m := quarterPattern.FindStringSubmatch(key); m != nil {
+		year, _ := strconv.Atoi(m[2])
+		quarter, _ := strconv.Atoi(m[3])
+		start := date(year, time.January, 1)
+		if m[1] != "" {
+			start = fiscalYear(year, fiscalStartMonth).Start
+		}
+		start = start.AddDate(0, (quarter-1)*3, 0)
+		return Range{Start: start, End: start.AddDate(0, 3, -1), months: 3}, nil
+	}
+
+	if m := halfPattern.FindStringSubmatch(key); m != nil {
+		year, _ := strconv.Atoi(m[2])
+		half, _ := strconv.Atoi(m[3])
+		start := date(year, time.January, 1)
+		if m[1] != "" {
+			start = fiscalYear(year, fiscalStartMonth).Start
+		}
+		start = start.AddDate(0, (half-1)*6, 0)
+		return Range{Start: start, End: start.AddDate(0, 6, -1), months: 6}, nil
+	}
+
+	if m := fiscalPattern.FindStringSubmatch(key); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return fiscalYear(year, fiscalStartMonth), nil
+	}
+
+	return Range{}, fmt.Errorf("无法识别的周期: %s", key)
+}
+
+// ParseMonth 解析财年起始月份（1-12）
+func ParseMonth(value string) (time.Month, error) {
+	month, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || month < 1 || month > 12 {
+		return 0, fmt.Errorf("月份必须是 1-12 之间的整数: %s", value)
+	}
+	return time.Month(month), nil
+}
+
+// truncateDay 将时间截断到当天零点
+func truncateDay(t time.Time) time.Time {
+	return date(t.Year(), t.Month(), t.Day())
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// weekStart 返回所在周的周一
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+func monthStart(t time.Time) time.Time {
+	return date(t.Year(), t.Month(), 1)
+}
+
+func quarterStart(t time.Time) time.Time {
+	month := time.Month((int(t.Month())-1)/3*3 + 1)
+	return date(t.Year(), month, 1)
+}
+
+func calendarYear(year int) Range {
+	return Range{Start: date(year, time.January, 1), End: date(year, time.December, 31), months: 12}
+}
+
+// fiscalYear 返回指定财年的完整范围
+func fiscalYear(year int, startMonth time.Month) Range {
+	start := date(year, startMonth, 1)
+	return Range{Start: start, End: start.AddDate(1, 0, -1), months: 12}
+}
+
+// fiscalYearOf 返回日期所在的财年
+func fiscalYearOf(t time.Time, startMonth time.Month) int {
+	if t.Month() < startMonth {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// Previous 返回紧邻当前范围之前的时间范围。按月、季度、半年或年命名的周期返回上一个自然周期
+// （统计到今天的周期返回上一个周期的同期），其余范围返回长度相同的天数
+func (r Range) Previous() Range {
+	if r.months > 0 {
+		previous := r.shiftMonths(-r.months)
+		previous.Name = "previous"
+		return previous
+	}
+	days := int(r.End.Sub(r.Start).Hours()/24+0.5) + 1
+	end := r.Start.AddDate(0, 0, -1)
+	return Range{Name: "previous", Start: end.AddDate(0, 0, -(days - 1)), End: end}
+}
+
+// shiftMonths 将范围平移 n 个月。完整的命名周期平移后仍是完整周期，
+// 其余日期超出目标月份的天数时取该月最后一天，如 2024-02-29 平移 -12 个月为 2023-02-28
+func (r Range) shiftMonths(n int) Range {
+	start := addMonths(r.Start, n)
+	end := addMonths(r.End, n)
+	if r.months > 0 && r.End.Equal(r.Start.AddDate(0, r.months, -1)) {
+		end = start.AddDate(0, r.months, -1)
+	}
+	return Range{Start: start, End: end, months: r.months}
+}
+
+// addMonths 将日期平移 n 个月，日期超出目标月份的天数时取该月最后一天
+func addMonths(t time.Time, n int) time.Time {
+	first := date(t.Year(), t.Month()+time.Month(n), 1)
+	last := first.AddDate(0, 1, -1).Day()
+	return date(first.Year(), first.Month(), min(t.Day(), last))
+}
+
+// ResolveComparison 解析对比时间范围
+//
+// 支持 previous（紧邻的上一个等长周期）、previous-year（去年同期）、
+// <start>:<end>（YYYY-MM-DD:YYYY-MM-DD）以及 Resolve 支持的周期表达式。
+func ResolveComparison(expr string, current Range, now time.Time, fiscalStartMonth time.Month) (Range, error) {
+	expr = strings.TrimSpace(expr)
+	switch strings.ToLower(expr) {
+	case "previous":
+		return current.Previous(), nil
+	case "previous-year":
+		previous := current.shiftMonths(-12)
+		previous.Name = "previous-year"
+		return previous, nil
+	}
+
+	if parts := strings.SplitN(expr, ":", 2); len(parts) == 2 {
+		r, err := NewRange(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		if err != nil {
+			return Range{}, fmt.Errorf("对比范围无效: %v", err)
+		}
+		return r, nil
+	}
+
+	return Resolve(expr, now, fiscalStartMonth)
+}
@@ -0,0 +1,151 @@
+package period
+
+import (
+	"testing"
+	"time"
+)
+
+// 2026-01-07 是周三
+var testNow = time.Date(2026, time.January, 7, 15, 30, 0, 0, time.Local)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		expr        string
+		now         time.Time
+		fiscalStart time.Month
+		start, end  string
+	}{
+		{"today", testNow, time.January, "2026-01-07", "2026-01-07"},
+		{"yesterday", time.Date(2026, time.January, 1, 8, 0, 0, 0, time.Local), time.January, "2025-12-31", "2025-12-31"},
+		{"last-7d", testNow, time.January, "2026-01-01", "2026-01-07"},
+		{"last-2w", testNow, time.January, "2025-12-25", "2026-01-07"},
+
+		// 周一至周日，跨年
+		{"this-week", testNow, time.January, "2026-01-05", "2026-01-07"},
+		{"this-week", time.Date(2026, time.January, 11, 23, 0, 0, 0, time.Local), time.January, "2026-01-05", "2026-01-11"},
+		{"last-week", testNow, time.January, "2025-12-29", "2026-01-04"},
+		{"last-week", time.Date(2026, time.January, 5, 0, 0, 0, 0, time.Local), time.January, "2025-12-29", "2026-01-04"},
+		{"last-week", time.Date(2026, time.January, 4, 0, 0, 0, 0, time.Local), time.January, "2025-12-22", "2025-12-28"},
+
+		// 月、季度、年，跨年
+		{"this-month", testNow, time.January, "2026-01-01", "2026-01-07"},
+		{"last-month", testNow, time.January, "2025-12-01", "2025-12-31"},
+		{"last-month", time.Date(2026, time.March, 31, 0, 0, 0, 0, time.Local), time.January, "2026-02-01", "2026-02-28"},
+		{"this-quarter", testNow, time.January, "2026-01-01", "2026-01-07"},
+		{"last-quarter", testNow, time.January, "2025-10-01", "2025-12-31"},
+		{"this-year", testNow, time.January, "2026-01-01", "2026-01-07"},
+		{"last-year", testNow, time.January, "2025-01-01", "2025-12-31"},
+		{"2026", testNow, time.January, "2026-01-01", "2026-12-31"},
+		{"2024-02", testNow, time.January, "2024-02-01", "2024-02-29"},
+		{"2026-Q3", testNow, time.January, "2026-07-01", "2026-09-30"},
+		{"2026-Q4", testNow, time.April, "2026-10-01", "2026-12-31"},
+		{"2026-H1", testNow, time.January, "2026-01-01", "2026-06-30"},
+		{"2026-H2", testNow, time.January, "2026-07-01", "2026-12-31"},
+
+		// 财年起始月份不为 1 月
+		{"this-fy", testNow, time.April, "2025-04-01", "2026-01-07"},
+		{"last-fy", testNow, time.April, "2024-04-01", "2025-03-31"},
+		{"this-fy", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.Local), time.April, "2026-04-01", "2026-04-01"},
+		{"last-fy", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.Local), time.April, "2025-04-01", "2026-03-31"},
+		{"this-fy", testNow, time.January, "2026-01-01", "2026-01-07"},
+		{"FY2026", testNow, time.July, "2026-07-01", "2027-06-30"},
+		{"FY2026-Q1", testNow, time.April, "2026-04-01", "2026-06-30"},
+		{"FY2026-Q4", testNow, time.April, "2027-01-01", "2027-03-31"},
+		{"FY2026-H2", testNow, time.April, "2026-10-01", "2027-03-31"},
+		{"fy2026-h1", testNow, time.October, "2026-10-01", "2027-03-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr+"@"+tt.now.Format(DateLayout)+"/"+tt.fiscalStart.String(), func(t *testing.T) {
+			r, err := Resolve(tt.expr, tt.now, tt.fiscalStart)
+			if err != nil {
+				t.Fatalf("Resolve(%q): %v", tt.expr, err)
+			}
+			if r.StartDate() != tt.start || r.EndDate() != tt.end {
+				t.Errorf("Resolve(%q) = %s ~ %s, want %s ~ %s", tt.expr, r.StartDate(), r.EndDate(), tt.start, tt.end)
+			}
+			if r.Name != tt.expr {
+				t.Errorf("Resolve(%q).Name = %q", tt.expr, r.Name)
+			}
+		})
+	}
+}
+
+func TestResolveInvalid(t *testing.T) {
+	tests := []struct {
+		expr        string
+		fiscalStart time.Month
+	}{
+		{"2026-13", time.January},
+		{"2026-Q5", time.January},
+		{"last-0d", time.January},
+		{"next-week", time.January},
+		{"this-fy", 0},
+		{"FY2026", 13},
+	}
+	for _, tt := range tests {
+		if r, err := Resolve(tt.expr, testNow, tt.fiscalStart); err == nil {
+			t.Errorf("Resolve(%q, %d) = %v, want error", tt.expr, tt.fiscalStart, r)
+		}
+	}
+}
+
+func TestResolveComparison(t *testing.T) {
+	// 通过 -s/-e 指定的范围
+	custom := func(start, end string) Range {
+		r, err := NewRange(start, end)
+		if err != nil {
+			t.Fatalf("NewRange(%s, %s): %v", start, end, err)
+		}
+		return r
+	}
+	// 周期表达式解析出的范围
+	named := func(expr string, now time.Time, fiscal time.Month) Range {
+		r, err := Resolve(expr, now, fiscal)
+		if err != nil {
+			t.Fatalf("Resolve(%q): %v", expr, err)
+		}
+		return r
+	}
+	endOfMarch := time.Date(2026, time.March, 31, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		expr       string
+		current    Range
+		fiscal     time.Month
+		start, end string
+	}{
+		{"自定义范围的上一个等长周期跨年", "previous", custom("2026-01-01", "2026-01-31"), time.January, "2025-12-01", "2025-12-31"},
+		{"自定义范围按天数计算", "previous", custom("2026-03-01", "2026-03-31"), time.January, "2026-01-29", "2026-02-28"},
+		{"最近 N 天按天数计算", "previous", named("last-7d", testNow, time.January), time.January, "2025-12-25", "2025-12-31"},
+		{"上一周", "previous", named("last-week", testNow, time.January), time.January, "2025-12-22", "2025-12-28"},
+		{"月份的上一个自然月", "previous", named("2026-03", testNow, time.January), time.January, "2026-02-01", "2026-02-28"},
+		{"月份的上一个自然月跨年", "previous", named("2026-01", testNow, time.January), time.January, "2025-12-01", "2025-12-31"},
+		{"季度的上一个季度", "previous", named("2026-Q2", testNow, time.January), time.January, "2026-01-01", "2026-03-31"},
+		{"半年的上一个半年", "previous", named("2026-H1", testNow, time.January), time.January, "2025-07-01", "2025-12-31"},
+		{"财年的上一个财年", "previous", named("FY2026", testNow, time.April), time.April, "2025-04-01", "2026-03-31"},
+		{"本月至今对比上月同期", "previous", named("this-month", endOfMarch, time.January), time.January, "2026-02-01", "2026-02-28"},
+		{"本季度至今对比上季度同期", "previous", named("this-quarter", testNow, time.January), time.January, "2025-10-01", "2025-10-07"},
+		{"去年同期", "previous-year", named("2026-Q1", testNow, time.January), time.January, "2025-01-01", "2025-03-31"},
+		{"闰年二月的去年同期", "previous-year", named("2024-02", testNow, time.January), time.January, "2023-02-01", "2023-02-28"},
+		{"自定义范围的去年同期不超出月末", "previous-year", custom("2024-02-10", "2024-02-29"), time.January, "2023-02-10", "2023-02-28"},
+		{"指定日期范围", "2025-06-01:2025-06-30", custom("2026-01-01", "2026-01-31"), time.January, "2025-06-01", "2025-06-30"},
+		{"周期表达式", "last-fy", custom("2026-01-01", "2026-01-31"), time.April, "2024-04-01", "2025-03-31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ResolveComparison(tt.expr, tt.current, testNow, tt.fiscal)
+			if err != nil {
+				t.Fatalf("ResolveComparison(%q): %v", tt.expr, err)
+			}
+			if r.StartDate() != tt.start || r.EndDate() != tt.end {
+				t.Errorf("ResolveComparison(%q) = %s ~ %s, want %s ~ %s", tt.expr, r.StartDate(), r.EndDate(), tt.start, tt.end)
+			}
+		})
+	}
+
+	if _, err := ResolveComparison("2025-06-30:2025-06-01", custom("2026-01-01", "2026-01-31"), testNow, time.January); err == nil {
+		t.Errorf("结束日期早于开始日期时应返回错误")
+	}
+}
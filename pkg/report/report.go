@@ -0,0 +1,257 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+	"github.com/doufum/gitlab-analyze/pkg/team"
+)
+
+// RunMetadata 统计运行的元数据，随导出结果一起保存
+type RunMetadata struct {
+	Period      string
+	StartDate   string
+	EndDate     string
+	ProjectIDs  []string
+	TargetUsers []string
+	TeamFile    string
+	// 生效的作者排除规则
+	ExclusionRules []string
+	// 异常提交策略描述
+	OutlierPolicy string
+	// 贡献统计方式：gross 或 net
+	ContributionMode string
+	// 代码量归属方式：all 或 merged-mr
+	Attribution string
+	// 自定义提交分类规则
+	CommitCategories []string
+	// 任务编号规则
+	TicketPatterns []string
+	// 时间分桶粒度：week 或 month，为空表示不分桶
+	Bucket string
+
+	GeneratedAt time.Time
+	// 统计完成时间，导出前填充
+	FinishedAt time.Time
+	// GitLab 实例地址和工具版本
+	GitLabURL   string
+	ToolVersion string
+
+	// 对比时间段，未启用对比时为空
+	CompareTo        string
+	CompareStartDate string
+	CompareEndDate   string
+}
+
+// Timestamp 返回用于文件名的生成时间戳
+func (m RunMetadata) Timestamp() string {
+	return m.GeneratedAt.Format("20060102_150405")
+}
+
+// Fields 以键值对的形式返回元数据，顺序固定
+func (m RunMetadata) Fields() [][2]string {
+	period := m.Period
+	if period == "" {
+		period = "自定义"
+	}
+	targetUsers := strings.Join(m.TargetUsers, ",")
+	if targetUsers == "" {
+		targetUsers = "全部"
+	}
+
+	fields := [][2]string{
+		{"统计周期", period},
+		{"开始日期", m.StartDate},
+		{"结束日期", m.EndDate},
+		{"项目数量", strconv.Itoa(len(m.ProjectIDs))},
+		{"项目 ID", strings.Join(m.ProjectIDs, ",")},
+		{"目标用户", targetUsers},
+	}
+	if m.TeamFile != "" {
+		fields = append(fields, [2]string{"团队映射文件", m.TeamFile})
+	}
+	if len(m.ExclusionRules) > 0 {
+		fields = append(fields, [2]string{"排除规则", strings.Join(m.ExclusionRules, "; ")})
+	}
+	if m.ContributionMode != "" {
+		fields = append(fields, [2]string{"贡献统计方式", m.ContributionMode})
+	}
+	if m.Attribution != "" {
+		fields = append(fields, [2]string{"代码量归属方式", m.Attribution})
+	}
+	if len(m.CommitCategories) > 0 {
+		fields = append(fields, [2]string{"自定义提交分类", strings.Join(m.CommitCategories, "; ")})
+	}
+	if len(m.TicketPatterns) > 0 {
+		fields = append(fields, [2]string{"任务编号规则", strings.Join(m.TicketPatterns, "; ")})
+	}
+	if m.Bucket != "" {
+		fields = append(fields, [2]string{"时间分桶", m.Bucket})
+	}
+	if m.OutlierPolicy != "" {
+		fields = append(fields, [2]string{"异常提交策略", m.OutlierPolicy})
+	}
+	if m.CompareTo != "" {
+		fields = append(fields,
+			[2]string{"对比周期", m.CompareTo},
+			[2]string{"对比开始日期", m.CompareStartDate},
+			[2]string{"对比结束日期", m.CompareEndDate},
+		)
+	}
+	if m.GitLabURL != "" {
+		fields = append(fields, [2]string{"GitLab 地址", m.GitLabURL})
+	}
+	if m.ToolVersion != "" {
+		fields = append(fields, [2]string{"工具版本", m.ToolVersion})
+	}
+	fields = append(fields, [2]string{"生成时间", m.GeneratedAt.Format("2006-01-02 15:04:05")})
+	if !m.FinishedAt.IsZero() {
+		fields = append(fields, [2]string{"耗时(秒)", strconv.FormatFloat(m.Duration().Seconds(), 'f', 1, 64)})
+	}
+	return fields
+}
+
+// Duration 统计耗时，未完成时为 0
+func (m RunMetadata) Duration() time.Duration {
+	if m.FinishedAt.IsZero() {
+		return 0
+	}
+	return m.FinishedAt.Sub(m.GeneratedAt)
+}
+
+// Table 以结果表的形式返回元数据
+func (m RunMetadata) Table() Table {
+	table := Table{Name: "metadata", Title: "运行元数据", Header: []string{"字段", "值"}}
+	for _, field := range m.Fields() {
+		table.Rows = append(table.Rows, []interface{}{field[0], field[1]})
+	}
+	return table
+}
+
+// 获取数据的阶段，用于记录失败信息
+const (
+	StageCommits        = "commits"
+	StageCompareCommits = "compare_commits"
+	StageMergeRequests  = "merge_requests"
+	StageDirectPushes   = "direct_pushes"
+	StagePipelines      = "pipelines"
+	StageDeployments    = "deployments"
+	StageIssues         = "issues"
+)
+
+// Failure 统计过程中获取某个项目的数据失败的记录，失败的项目不计入对应阶段的结果
+type Failure struct {
+	ProjectID string
+	Stage     string
+	Error     string
+}
+
+// Report 一次统计运行的完整结果
+type Report struct {
+	Meta     RunMetadata
+	Stats    map[string]gitlab.UserStats
+	Projects []gitlab.ProjectInfo
+	// 计入统计的提交
+	Commits []gitlab.Commit
+
+	// 对比时间段的结果，未启用对比时为 nil
+	Comparison *gitlab.Comparison
+	// 团队和部门汇总，未指定团队映射文件时为 nil
+	Teams *team.Rollup
+	// 被排除规则过滤掉的作者
+	Excluded []gitlab.ExcludedAuthor
+	// 检测到的异常提交
+	Outliers []gitlab.OutlierCommit
+	// 识别到的回滚提交
+	Reverts []gitlab.RevertPair
+	// 按提交类型和自定义分类的统计
+	Categories []gitlab.CategoryBreakdown
+	// 任务编号分析结果
+	Tickets *gitlab.TicketReport
+
+	// 合并请求明细及汇总，未启用合并请求统计时为 nil
+	MergeRequests     []gitlab.MergeRequest
+	MergeRequestStats *gitlab.MergeRequestReport
+	// 代码评审参与情况
+	Reviews *gitlab.ReviewReport
+	// 直接推送到默认分支或受保护分支的提交，未启用检查时为 nil
+	DirectPushes []gitlab.DirectPush
+	// CI 流水线统计
+	Pipelines *gitlab.PipelineReport
+	// DORA 指标
+	DORA *gitlab.DORAReport
+	// 议题统计
+	Issues *gitlab.IssueReport
+	// 按时间区间的统计，未启用时间分桶时为空
+	Buckets []gitlab.Bucket
+	// 获取数据失败的项目
+	Failures []Failure
+}
+
+// AddFailure 记录获取项目数据失败
+func (r *Report) AddFailure(projectID, stage string, err error) {
+	r.Failures = append(r.Failures, Failure{ProjectID: projectID, Stage: stage, Error: err.Error()})
+}
+
+// Project 根据项目 ID 查找项目信息，未找到时仅填充 ID
+func (r *Report) Project(projectID string) gitlab.ProjectInfo {
+	for _, project := range r.Projects {
+		if project.ID == projectID {
+			return project
+		}
+	}
+	return gitlab.ProjectInfo{ID: projectID}
+}
+
+// Tables 返回除用户统计外的附加结果表，按固定顺序排列
+func (r *Report) Tables() []Table {
+	var tables []Table
+	if r.Comparison != nil {
+		tables = append(tables, r.comparisonTables()...)
+	}
+	if r.Teams != nil {
+		tables = append(tables, r.teamTables()...)
+	}
+	if len(r.Excluded) > 0 {
+		tables = append(tables, r.excludedTable())
+	}
+	if len(r.Outliers) > 0 {
+		tables = append(tables, r.outlierTable())
+	}
+	if len(r.Reverts) > 0 {
+		tables = append(tables, r.revertTable())
+	}
+	if len(r.Categories) > 0 {
+		tables = append(tables, r.categoryTables()...)
+	}
+	if r.Tickets != nil {
+		tables = append(tables, r.ticketTables()...)
+	}
+	if r.MergeRequestStats != nil {
+		tables = append(tables, r.mergeRequestTables()...)
+	}
+	if r.Reviews != nil {
+		tables = append(tables, r.reviewTables()...)
+	}
+	if r.DirectPushes != nil {
+		tables = append(tables, r.directPushTables()...)
+	}
+	if r.Pipelines != nil {
+		tables = append(tables, r.pipelineTables()...)
+	}
+	if r.DORA != nil {
+		tables = append(tables, r.doraTables()...)
+	}
+	if r.Issues != nil {
+		tables = append(tables, r.issueTables()...)
+	}
+	if len(r.Buckets) > 0 {
+		tables = append(tables, r.bucketTable())
+	}
+	if len(r.Failures) > 0 {
+		tables = append(tables, r.failureTable())
+	}
+	return tables
+}
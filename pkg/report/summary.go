@@ -0,0 +1,235 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+)
+
+// ProjectTotal 项目维度的代码量汇总
+type ProjectTotal struct {
+	gitlab.ProjectInfo
+	gitlab.ProjectStats
+	// 在项目中有提交的用户数
+	Contributors int
+}
+
+// SortedUsers 返回按总代码量降序排列的用户名，总代码量相同时按用户名排序
+func (r *Report) SortedUsers() []string {
+	users := make([]string, 0, len(r.Stats))
+	for user := range r.Stats {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		a, b := r.Stats[users[i]], r.Stats[users[j]]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return users[i] < users[j]
+	})
+	return users
+}
+
+// ProjectTotals 汇总所有用户在各项目中的代码量，按总代码量降序排列
+func (r *Report) ProjectTotals() []ProjectTotal {
+	totals := make(map[string]*ProjectTotal)
+	for _, stat := range r.Stats {
+		for projectID, s := range stat.Projects {
+			total := totals[projectID]
+			if total == nil {
+				total = &ProjectTotal{ProjectInfo: r.Project(projectID)}
+				totals[projectID] = total
+			}
+			total.Commits += s.Commits
+			total.Additions += s.Additions
+			total.Deletions += s.Deletions
+			total.Changes += s.Changes
+			total.Reverted += s.Reverted
+			total.Contributors++
+		}
+	}
+
+	result := make([]ProjectTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].Additions+result[i].Deletions, result[j].Additions+result[j].Deletions
+		if a != b {
+			return a > b
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// SummaryTable 生成整体汇总表
+func (r *Report) SummaryTable() Table {
+	var total gitlab.UserStats
+	for _, stat := range r.Stats {
+		total.Commits += stat.Commits
+		total.Additions += stat.Additions
+		total.Deletions += stat.Deletions
+		total.Changes += stat.Changes
+		total.Total += stat.Total
+		total.Reverted += stat.Reverted
+	}
+
+	table := Table{Name: "summary", Title: "汇总", Header: []string{"指标", "值"}}
+	table.Rows = [][]interface{}{
+		{"开始日期", r.Meta.StartDate},
+		{"结束日期", r.Meta.EndDate},
+		{"用户数", len(r.Stats)},
+		{"有提交的项目数", len(r.ProjectTotals())},
+	}
+	for i, name := range statsHeader() {
+		table.Rows = append(table.Rows, []interface{}{name, userStatsCells(total)[i]})
+	}
+	if users := r.SortedUsers(); len(users) > 0 {
+		table.Rows = append(table.Rows, []interface{}{"总代码量最多的用户", users[0]})
+	}
+	return table
+}
+
+// UserTable 生成用户汇总表
+func (r *Report) UserTable() Table {
+	table := Table{
+		Name:   "users",
+		Title:  "用户统计",
+		Header: append(append([]string{"用户名"}, statsHeader()...), "项目数"),
+	}
+	for _, user := range r.SortedUsers() {
+		stat := r.Stats[user]
+		row := append([]interface{}{user}, userStatsCells(stat)...)
+		table.Rows = append(table.Rows, append(row, len(stat.Projects)))
+	}
+	return table
+}
+
+// ProjectTable 生成项目汇总表
+func (r *Report) ProjectTable() Table {
+	table := Table{
+		Name:   "projects",
+		Title:  "项目统计",
+		Header: append(append([]string{"项目 ID", "项目名称", "项目路径"}, statsHeader()...), "贡献者数"),
+	}
+	for _, total := range r.ProjectTotals() {
+		row := append([]interface{}{total.ID, total.Name, total.PathWithNamespace}, statsCells(total.ProjectStats)...)
+		table.Rows = append(table.Rows, append(row, total.Contributors))
+	}
+	return table
+}
+
+// ProjectUserTable 生成单个项目中各用户的统计表，按总代码量降序排列
+func (r *Report) ProjectUserTable(projectID string) Table {
+	table := Table{
+		Name:   "project_users",
+		Title:  "项目用户统计",
+		Header: append([]string{"用户名"}, statsHeader()...),
+	}
+	var users []string
+	for user, stat := range r.Stats {
+		if _, ok := stat.Projects[projectID]; ok {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		a, b := r.Stats[users[i]].Projects[projectID], r.Stats[users[j]].Projects[projectID]
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		return users[i] < users[j]
+	})
+	for _, user := range users {
+		table.Rows = append(table.Rows, append([]interface{}{user}, statsCells(r.Stats[user].Projects[projectID])...))
+	}
+	return table
+}
+
+// MatrixTable 生成用户×项目的总代码量矩阵，行为用户，列为项目
+func (r *Report) MatrixTable() Table {
+	projects := r.ProjectTotals()
+	table := Table{Name: "user_project_matrix", Title: "用户×项目", Header: []string{"用户名"}}
+	for _, project := range projects {
+		name := project.PathWithNamespace
+		if name == "" {
+			name = project.ID
+		}
+		table.Header = append(table.Header, name)
+	}
+	table.Header = append(table.Header, "合计")
+
+	for _, user := range r.SortedUsers() {
+		stat := r.Stats[user]
+		row := []interface{}{user}
+		for _, project := range projects {
+			s := stat.Projects[project.ID]
+			row = append(row, s.Additions+s.Deletions)
+		}
+		table.Rows = append(table.Rows, append(row, stat.Total))
+	}
+	return table
+}
+
+// TrendTable 生成各时间区间的总代码量趋势表，列为总代码量最多的 limit 个用户和全部用户合计
+func (r *Report) TrendTable(limit int) Table {
+	users := r.SortedUsers()
+	if limit > 0 && len(users) > limit {
+		users = users[:limit]
+	}
+	table := Table{Name: "trend", Title: "代码量趋势", Header: append(append([]string{"区间"}, users...), "合计")}
+	for _, bucket := range r.Buckets {
+		row := []interface{}{bucket.Label}
+		total := 0
+		for _, user := range users {
+			row = append(row, bucket.Stats[user].Total)
+		}
+		for _, stat := range bucket.Stats {
+			total += stat.Total
+		}
+		table.Rows = append(table.Rows, append(row, total))
+	}
+	return table
+}
+
+// ConsolidatedTable 生成长格式的合并统计表，每个用户在每个项目（启用时间分桶时为每个区间的每个项目）一行，
+// 并附带运行元数据列，便于导入 BI 工具
+func (r *Report) ConsolidatedTable() Table {
+	table := Table{
+		Name:  "consolidated",
+		Title: "合并统计",
+		Header: []string{"生成时间", "统计周期", "开始日期", "结束日期", "贡献统计方式", "代码量归属方式", "时间分桶",
+			"区间", "区间开始日期", "用户名", "项目 ID", "项目名称", "项目路径",
+			"提交数", "增加行数", "删除行数", "净增行数", "变更行数", "总代码量", "被回滚行数"},
+	}
+	meta := []interface{}{
+		r.Meta.GeneratedAt.Format("2006-01-02 15:04:05"), r.Meta.Period, r.Meta.StartDate, r.Meta.EndDate,
+		r.Meta.ContributionMode, r.Meta.Attribution, r.Meta.Bucket,
+	}
+	addRows := func(label, start string, stats map[string]gitlab.UserStats, reverted bool) {
+		for _, user := range SortedKeys(stats) {
+			for _, projectID := range SortedKeys(stats[user].Projects) {
+				s := stats[user].Projects[projectID]
+				project := r.Project(projectID)
+				row := append(append([]interface{}{}, meta...), label, start, user, projectID, project.Name, project.PathWithNamespace,
+					s.Commits, s.Additions, s.Deletions, s.Additions-s.Deletions, s.Changes, s.Additions+s.Deletions)
+				// 被回滚行数不按区间统计
+				if reverted {
+					row = append(row, s.Reverted)
+				} else {
+					row = append(row, "")
+				}
+				table.Rows = append(table.Rows, row)
+			}
+		}
+	}
+
+	if len(r.Buckets) == 0 {
+		addRows("", "", r.Stats, true)
+		return table
+	}
+	for _, bucket := range r.Buckets {
+		addRows(bucket.Label, bucket.Start.Format("2006-01-02"), bucket.Stats, false)
+	}
+	return table
+}
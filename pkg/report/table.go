@@ -0,0 +1,880 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+	"github.com/doufum/gitlab-analyze/pkg/team"
+)
+
+// Table 与导出格式无关的二维结果表
+type Table struct {
+	// Name 用于文件名和工作表名的英文标识
+	Name string
+	// Title 中文标题
+	Title  string
+	Header []string
+	// Rows 中的单元格为 string、int 或 float64
+	Rows [][]interface{}
+}
+
+// 贡献者状态的中文描述（用户或项目在对比期不存在为新增，在当前期不存在为消失）
+var contributorStatusNames = map[string]string{
+	gitlab.ContributorActive:      "持续",
+	gitlab.ContributorNew:         "新增",
+	gitlab.ContributorDisappeared: "消失",
+}
+
+// ContributorStatusName 返回贡献者状态的中文描述
+func ContributorStatusName(status string) string {
+	return contributorStatusNames[status]
+}
+
+// deltaHeader 指标变化列的表头
+func deltaHeader() []string {
+	var header []string
+	for _, metric := range []string{"增加行数", "删除行数", "变更行数", "总代码量"} {
+		header = append(header, "当前"+metric, "对比"+metric, metric+"差值", metric+"变化率(%)")
+	}
+	return header
+}
+
+// deltaCells 指标变化列的单元格
+func deltaCells(d gitlab.StatsDelta) []interface{} {
+	var cells []interface{}
+	for _, m := range []gitlab.MetricDelta{d.Additions, d.Deletions, d.Changes, d.Total} {
+		var percent interface{} = ""
+		if m.HasPercent {
+			percent = roundPercent(m.Percent)
+		}
+		cells = append(cells, m.Current, m.Previous, m.Delta, percent)
+	}
+	return cells
+}
+
+// comparisonTables 生成用户和项目两个维度的对比表
+func (r *Report) comparisonTables() []Table {
+	users := Table{
+		Name:   "comparison_users",
+		Title:  "用户对比",
+		Header: append([]string{"用户名", "项目 ID", "项目名称", "项目路径", "状态"}, deltaHeader()...),
+	}
+	for _, user := range r.Comparison.Users {
+		row := []interface{}{user.User, "", "合计", "", ContributorStatusName(user.Status)}
+		users.Rows = append(users.Rows, append(row, deltaCells(user.StatsDelta)...))
+
+		for _, projectID := range SortedKeys(user.Projects) {
+			delta := user.Projects[projectID]
+			project := r.Project(projectID)
+			row := []interface{}{user.User, projectID, project.Name, project.PathWithNamespace, ContributorStatusName(delta.Status)}
+			users.Rows = append(users.Rows, append(row, deltaCells(delta)...))
+		}
+	}
+
+	projects := Table{
+		Name:   "comparison_projects",
+		Title:  "项目对比",
+		Header: append([]string{"项目 ID", "项目名称", "项目路径", "状态"}, deltaHeader()...),
+	}
+	for _, projectID := range SortedKeys(r.Comparison.Projects) {
+		delta := r.Comparison.Projects[projectID]
+		project := r.Project(projectID)
+		row := []interface{}{projectID, project.Name, project.PathWithNamespace, ContributorStatusName(delta.Status)}
+		projects.Rows = append(projects.Rows, append(row, deltaCells(delta)...))
+	}
+
+	return []Table{users, projects}
+}
+
+// statsHeader 代码量统计列的表头
+func statsHeader() []string {
+	return []string{"提交数", "增加行数", "删除行数", "变更行数", "总代码量", "被回滚行数"}
+}
+
+// statsCells 代码量统计列的单元格
+func statsCells(s gitlab.ProjectStats) []interface{} {
+	return []interface{}{s.Commits, s.Additions, s.Deletions, s.Changes, s.Additions + s.Deletions, s.Reverted}
+}
+
+// userStatsCells 用户合计的代码量统计单元格
+func userStatsCells(s gitlab.UserStats) []interface{} {
+	return []interface{}{s.Commits, s.Additions, s.Deletions, s.Changes, s.Total, s.Reverted}
+}
+
+// teamTables 生成团队、部门汇总表和未映射作者表
+func (r *Report) teamTables() []Table {
+	teams := Table{
+		Name:   "teams",
+		Title:  "团队汇总",
+		Header: append([]string{"团队", "部门", "成员数", "成员", "项目 ID", "项目名称", "项目路径"}, statsHeader()...),
+	}
+	for _, g := range r.Teams.Teams {
+		teams.Rows = append(teams.Rows, r.groupRows(g, []interface{}{g.Name, g.Department, len(g.Members), strings.Join(g.Members, ",")})...)
+	}
+
+	departments := Table{
+		Name:   "departments",
+		Title:  "部门汇总",
+		Header: append([]string{"部门", "成员数", "成员", "项目 ID", "项目名称", "项目路径"}, statsHeader()...),
+	}
+	for _, g := range r.Teams.Departments {
+		departments.Rows = append(departments.Rows, r.groupRows(g, []interface{}{g.Name, len(g.Members), strings.Join(g.Members, ",")})...)
+	}
+
+	unmapped := Table{
+		Name:   "unmapped_authors",
+		Title:  "未映射作者",
+		Header: append([]string{"用户名"}, statsHeader()...),
+	}
+	for _, user := range SortedKeys(r.Teams.Unmapped) {
+		unmapped.Rows = append(unmapped.Rows, append([]interface{}{user}, userStatsCells(r.Teams.Unmapped[user])...))
+	}
+
+	return []Table{teams, departments, unmapped}
+}
+
+// groupRows 生成团队或部门的合计行和各项目行
+func (r *Report) groupRows(g team.GroupStats, prefix []interface{}) [][]interface{} {
+	var rows [][]interface{}
+	row := append(append([]interface{}{}, prefix...), "", "合计", "")
+	rows = append(rows, append(row, userStatsCells(g.UserStats)...))
+
+	for _, projectID := range SortedKeys(g.Projects) {
+		project := r.Project(projectID)
+		row := append(append([]interface{}{}, prefix...), projectID, project.Name, project.PathWithNamespace)
+		rows = append(rows, append(row, statsCells(g.Projects[projectID])...))
+	}
+	return rows
+}
+
+// excludedTable 生成被排除作者的汇总表
+func (r *Report) excludedTable() Table {
+	table := Table{
+		Name:   "excluded",
+		Title:  "已排除的贡献",
+		Header: append([]string{"用户名", "邮箱", "排除原因", "项目 ID", "项目名称", "项目路径"}, statsHeader()...),
+	}
+	for _, author := range r.Excluded {
+		row := []interface{}{author.Author, author.Email, author.Reason, "", "合计", ""}
+		table.Rows = append(table.Rows, append(row, userStatsCells(author.UserStats)...))
+
+		for _, projectID := range SortedKeys(author.Projects) {
+			project := r.Project(projectID)
+			row := []interface{}{author.Author, author.Email, author.Reason, projectID, project.Name, project.PathWithNamespace}
+			table.Rows = append(table.Rows, append(row, statsCells(author.Projects[projectID])...))
+		}
+	}
+	return table
+}
+
+// 异常提交处理方式的中文描述
+var outlierActionNames = map[string]string{
+	gitlab.OutlierFlag:     "标记",
+	gitlab.OutlierCap:      "截断",
+	gitlab.OutlierExclude:  "排除",
+	gitlab.OutlierOverride: "人工放行",
+}
+
+// outlierTable 生成异常提交审核表
+func (r *Report) outlierTable() Table {
+	table := Table{
+		Name:  "outliers",
+		Title: "异常提交审核",
+		Header: []string{"项目 ID", "项目名称", "项目路径", "提交 SHA", "作者", "提交时间", "提交标题",
+			"增加行数", "删除行数", "总行数", "阈值", "规则", "处理方式"},
+	}
+	for _, o := range r.Outliers {
+		project := r.Project(o.ProjectID)
+		table.Rows = append(table.Rows, []interface{}{
+			o.ProjectID, project.Name, project.PathWithNamespace, o.ID, o.AuthorName,
+			o.CommittedDate.Format("2006-01-02 15:04:05"), o.Subject(),
+			o.Stats.Additions, o.Stats.Deletions, o.Lines, o.Threshold, o.Rule, outlierActionNames[o.Action],
+		})
+	}
+	return table
+}
+
+// 回滚识别方式的中文描述
+var revertMethodNames = map[string]string{
+	gitlab.RevertBySHA:   "提交信息",
+	gitlab.RevertByStats: "标题和反向行数",
+}
+
+// revertTable 生成回滚提交明细表
+func (r *Report) revertTable() Table {
+	table := Table{
+		Name:  "reverts",
+		Title: "回滚提交",
+		Header: []string{"项目 ID", "项目名称", "项目路径", "回滚提交 SHA", "回滚作者", "回滚时间", "回滚标题",
+			"原提交 SHA", "原提交作者", "原提交时间", "被回滚行数", "识别方式"},
+	}
+	for _, pair := range r.Reverts {
+		project := r.Project(pair.Revert.ProjectID)
+		row := []interface{}{
+			pair.Revert.ProjectID, project.Name, project.PathWithNamespace, pair.Revert.ID, pair.Revert.AuthorName,
+			pair.Revert.CommittedDate.Format("2006-01-02 15:04:05"), pair.Revert.Subject(), pair.OriginalID,
+		}
+		if pair.Original != nil {
+			row = append(row, pair.Original.AuthorName, pair.Original.CommittedDate.Format("2006-01-02 15:04:05"),
+				pair.Original.Stats.Additions+pair.Original.Stats.Deletions)
+		} else {
+			row = append(row, "", "不在统计范围内", "")
+		}
+		table.Rows = append(table.Rows, append(row, revertMethodNames[pair.Method]))
+	}
+	return table
+}
+
+// 分类维度和类型的中文描述
+var (
+	dimensionNames = map[string]string{
+		gitlab.DimensionType:     "提交类型",
+		gitlab.DimensionCategory: "自定义分类",
+	}
+	commitTypeNames = map[string]string{
+		"feat":                    "feat 新功能",
+		"fix":                     "fix 缺陷修复",
+		"refactor":                "refactor 重构",
+		"docs":                    "docs 文档",
+		"test":                    "test 测试",
+		"chore":                   "chore 杂项",
+		gitlab.TypeOther:          "other 其他约定式类型",
+		gitlab.TypeUnconventional: "none 非约定式提交",
+	}
+)
+
+// categoryKey 分类汇总的键：维度、分类、汇总对象（用户或项目）、项目
+type categoryKey struct {
+	dimension string
+	category  string
+	owner     string
+	projectID string
+}
+
+// categoryTables 生成按用户和按项目的分类统计表，占比以同一用户（项目）全部提交为基数
+func (r *Report) categoryTables() []Table {
+	userTotals := make(map[[2]string]gitlab.CategoryStats)
+	projectTotals := make(map[string]gitlab.CategoryStats)
+	byUser := make(map[categoryKey]gitlab.CategoryStats)
+	byProject := make(map[categoryKey]gitlab.CategoryStats)
+
+	for _, b := range r.Categories {
+		// 提交类型维度覆盖全部提交，用于计算基数
+		if b.Dimension == gitlab.DimensionType {
+			userTotals[[2]string{b.User, ""}] = addCategoryStats(userTotals[[2]string{b.User, ""}], b.CategoryStats)
+			userTotals[[2]string{b.User, b.ProjectID}] = addCategoryStats(userTotals[[2]string{b.User, b.ProjectID}], b.CategoryStats)
+			projectTotals[b.ProjectID] = addCategoryStats(projectTotals[b.ProjectID], b.CategoryStats)
+		}
+		userKey := categoryKey{b.Dimension, b.Category, b.User, ""}
+		byUser[userKey] = addCategoryStats(byUser[userKey], b.CategoryStats)
+		userProjectKey := categoryKey{b.Dimension, b.Category, b.User, b.ProjectID}
+		byUser[userProjectKey] = addCategoryStats(byUser[userProjectKey], b.CategoryStats)
+		projectKey := categoryKey{b.Dimension, b.Category, "", b.ProjectID}
+		byProject[projectKey] = addCategoryStats(byProject[projectKey], b.CategoryStats)
+	}
+
+	categoryHeader := []string{"维度", "分类", "提交数", "增加行数", "删除行数", "总代码量", "提交数占比(%)", "代码量占比(%)"}
+	users := Table{
+		Name:   "categories_users",
+		Title:  "用户提交分类",
+		Header: append([]string{"用户名", "项目 ID", "项目名称", "项目路径"}, categoryHeader...),
+	}
+	for _, key := range sortedCategoryKeys(byUser) {
+		project := gitlab.ProjectInfo{Name: "合计"}
+		if key.projectID != "" {
+			project = r.Project(key.projectID)
+		}
+		row := []interface{}{key.owner, key.projectID, project.Name, project.PathWithNamespace}
+		total := userTotals[[2]string{key.owner, key.projectID}]
+		users.Rows = append(users.Rows, append(row, categoryCells(key, byUser[key], total)...))
+	}
+
+	projects := Table{
+		Name:   "categories_projects",
+		Title:  "项目提交分类",
+		Header: append([]string{"项目 ID", "项目名称", "项目路径"}, categoryHeader...),
+	}
+	for _, key := range sortedCategoryKeys(byProject) {
+		project := r.Project(key.projectID)
+		row := []interface{}{key.projectID, project.Name, project.PathWithNamespace}
+		projects.Rows = append(projects.Rows, append(row, categoryCells(key, byProject[key], projectTotals[key.projectID])...))
+	}
+
+	return []Table{users, projects}
+}
+
+// categoryCells 分类统计列的单元格
+func categoryCells(key categoryKey, stats, total gitlab.CategoryStats) []interface{} {
+	category := key.category
+	if key.dimension == gitlab.DimensionType {
+		category = commitTypeNames[key.category]
+	}
+	lines := stats.Additions + stats.Deletions
+	return []interface{}{
+		dimensionNames[key.dimension], category, stats.Commits, stats.Additions, stats.Deletions, lines,
+		share(stats.Commits, total.Commits), share(lines, total.Additions+total.Deletions),
+	}
+}
+
+// addCategoryStats 累加分类统计
+func addCategoryStats(a, b gitlab.CategoryStats) gitlab.CategoryStats {
+	a.Commits += b.Commits
+	a.Additions += b.Additions
+	a.Deletions += b.Deletions
+	return a
+}
+
+// sortedCategoryKeys 按汇总对象、项目（合计在前）、维度、分类排序
+func sortedCategoryKeys(m map[categoryKey]gitlab.CategoryStats) []categoryKey {
+	keys := make([]categoryKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.owner != b.owner {
+			return a.owner < b.owner
+		}
+		if a.projectID != b.projectID {
+			return a.projectID < b.projectID
+		}
+		if a.dimension != b.dimension {
+			return a.dimension > b.dimension
+		}
+		return a.category < b.category
+	})
+	return keys
+}
+
+// share 计算占比百分比，基数为 0 时返回 0
+func share(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundPercent(float64(part) / float64(total) * 100)
+}
+
+// ticketTables 生成任务编号、编号前缀和可追溯性统计表
+func (r *Report) ticketTables() []Table {
+	ticketHeader := []string{"提交数", "增加行数", "删除行数", "总代码量", "参与用户"}
+
+	tickets := Table{
+		Name:   "tickets",
+		Title:  "任务编号",
+		Header: append([]string{"任务编号", "编号前缀", "项目 ID", "项目路径"}, ticketHeader...),
+	}
+	for _, t := range r.Tickets.Tickets {
+		var projectPath string
+		if t.ProjectID != "" {
+			projectPath = r.Project(t.ProjectID).PathWithNamespace
+		}
+		row := []interface{}{t.Key, t.Prefix, t.ProjectID, projectPath}
+		tickets.Rows = append(tickets.Rows, append(row, ticketCells(t)...))
+	}
+
+	prefixes := Table{
+		Name:   "ticket_prefixes",
+		Title:  "任务编号前缀",
+		Header: append([]string{"编号前缀"}, ticketHeader...),
+	}
+	for _, t := range r.Tickets.Prefixes {
+		prefixes.Rows = append(prefixes.Rows, append([]interface{}{t.Key}, ticketCells(t)...))
+	}
+
+	// 汇总每个用户和每个项目的可追溯情况
+	userTotals := make(map[string]gitlab.TraceabilityStats)
+	projectTotals := make(map[string]gitlab.TraceabilityStats)
+	for _, t := range r.Tickets.Traceability {
+		userTotals[t.User] = addTraceability(userTotals[t.User], t)
+		projectTotals[t.ProjectID] = addTraceability(projectTotals[t.ProjectID], t)
+	}
+
+	traceability := Table{
+		Name:  "traceability",
+		Title: "提交可追溯性",
+		Header: []string{"用户名", "项目 ID", "项目名称", "项目路径", "提交数", "引用任务的提交数", "未引用任务的提交数",
+			"可追溯比例(%)", "未引用比例(%)", "未引用任务的代码量"},
+	}
+	for _, user := range SortedKeys(userTotals) {
+		traceability.Rows = append(traceability.Rows, append([]interface{}{user, "", "合计", ""}, traceabilityCells(userTotals[user])...))
+		for _, t := range r.Tickets.Traceability {
+			if t.User == user {
+				project := r.Project(t.ProjectID)
+				row := []interface{}{user, t.ProjectID, project.Name, project.PathWithNamespace}
+				traceability.Rows = append(traceability.Rows, append(row, traceabilityCells(t)...))
+			}
+		}
+	}
+	for _, projectID := range SortedKeys(projectTotals) {
+		project := r.Project(projectID)
+		row := []interface{}{"合计", projectID, project.Name, project.PathWithNamespace}
+		traceability.Rows = append(traceability.Rows, append(row, traceabilityCells(projectTotals[projectID])...))
+	}
+
+	return []Table{tickets, prefixes, traceability}
+}
+
+// ticketCells 任务编号统计列的单元格
+func ticketCells(t gitlab.TicketStats) []interface{} {
+	return []interface{}{t.Commits, t.Additions, t.Deletions, t.Additions + t.Deletions, strings.Join(t.Users, ",")}
+}
+
+// traceabilityCells 可追溯性统计列的单元格
+func traceabilityCells(t gitlab.TraceabilityStats) []interface{} {
+	unreferenced := t.Commits - t.Referenced
+	return []interface{}{t.Commits, t.Referenced, unreferenced, share(t.Referenced, t.Commits), share(unreferenced, t.Commits), t.UnreferencedLines}
+}
+
+// addTraceability 累加可追溯性统计
+func addTraceability(a, b gitlab.TraceabilityStats) gitlab.TraceabilityStats {
+	a.Commits += b.Commits
+	a.Referenced += b.Referenced
+	a.Additions += b.Additions
+	a.Deletions += b.Deletions
+	a.UnreferencedLines += b.UnreferencedLines
+	return a
+}
+
+// mergeRequestTables 生成合并请求的用户、项目汇总表和明细表
+func (r *Report) mergeRequestTables() []Table {
+	stats := r.MergeRequestStats
+	mrHeader := []string{"创建数", "合并数", "关闭数", "已评审数", "首次评审平均时长(小时)", "首次评审中位时长(小时)",
+		"合并平均时长(小时)", "合并中位时长(小时)", "合并代码量", "平均代码量", "平均版本数"}
+
+	users := Table{
+		Name:   "merge_requests_users",
+		Title:  "用户合并请求",
+		Header: append([]string{"用户名", "项目 ID", "项目名称", "项目路径"}, mrHeader...),
+	}
+	for _, user := range SortedKeys(stats.Users) {
+		users.Rows = append(users.Rows, append([]interface{}{user, "", "合计", ""}, mergeRequestCells(stats.Users[user])...))
+		var projectIDs []string
+		for key := range stats.UserProjects {
+			if key[0] == user {
+				projectIDs = append(projectIDs, key[1])
+			}
+		}
+		sort.Strings(projectIDs)
+		for _, projectID := range projectIDs {
+			project := r.Project(projectID)
+			row := []interface{}{user, projectID, project.Name, project.PathWithNamespace}
+			users.Rows = append(users.Rows, append(row, mergeRequestCells(stats.UserProjects[[2]string{user, projectID}])...))
+		}
+	}
+
+	projects := Table{
+		Name:   "merge_requests_projects",
+		Title:  "项目合并请求",
+		Header: append([]string{"项目 ID", "项目名称", "项目路径"}, mrHeader...),
+	}
+	for _, projectID := range SortedKeys(stats.Projects) {
+		project := r.Project(projectID)
+		row := []interface{}{projectID, project.Name, project.PathWithNamespace}
+		projects.Rows = append(projects.Rows, append(row, mergeRequestCells(stats.Projects[projectID])...))
+	}
+
+	details := Table{
+		Name:  "merge_requests",
+		Title: "合并请求明细",
+		Header: []string{"项目 ID", "项目路径", "编号", "标题", "作者", "状态", "源分支", "目标分支", "创建时间", "首次评审时间",
+			"合并时间", "关闭时间", "增加行数", "删除行数", "版本数", "链接"},
+	}
+	for _, mr := range r.MergeRequests {
+		details.Rows = append(details.Rows, []interface{}{
+			mr.ProjectID, r.Project(mr.ProjectID).PathWithNamespace, mr.IID, mr.Title, mr.AuthorName(), mr.State,
+			mr.SourceBranch, mr.TargetBranch, formatTime(&mr.CreatedAt), formatTime(mr.FirstReviewAt()),
+			formatTime(mr.MergedAt), formatTime(mr.ClosedAt), mr.Additions, mr.Deletions, mr.Revisions, mr.WebURL,
+		})
+	}
+
+	return []Table{users, projects, details}
+}
+
+// reviewTables 生成用户评审统计表和评审人-作者矩阵
+func (r *Report) reviewTables() []Table {
+	users := Table{
+		Name:   "reviews_users",
+		Title:  "代码评审",
+		Header: []string{"用户名", "评审合并请求数", "评论数", "批准数", "跨项目评审数", "跨项目评审占比(%)", "评审项目数", "评审项目"},
+	}
+	for _, user := range SortedKeys(r.Reviews.Users) {
+		s := r.Reviews.Users[user]
+		var paths []string
+		for _, projectID := range s.Projects {
+			path := r.Project(projectID).PathWithNamespace
+			if path == "" {
+				path = projectID
+			}
+			paths = append(paths, path)
+		}
+		users.Rows = append(users.Rows, []interface{}{
+			user, s.Reviews, s.Comments, s.Approvals, s.CrossProjectReviews, share(s.CrossProjectReviews, s.Reviews),
+			len(s.Projects), strings.Join(paths, ","),
+		})
+	}
+
+	// 行为评审人，列为作者，单元格为评审的合并请求数
+	reviewers, authors := r.Reviews.ReviewMatrixUsers()
+	matrix := Table{
+		Name:   "review_matrix",
+		Title:  "评审人-作者矩阵",
+		Header: append(append([]string{"评审人/作者"}, authors...), "合计"),
+	}
+	for _, reviewer := range reviewers {
+		row := []interface{}{reviewer}
+		total := 0
+		for _, author := range authors {
+			count := r.Reviews.Matrix[[2]string{reviewer, author}]
+			total += count
+			row = append(row, count)
+		}
+		matrix.Rows = append(matrix.Rows, append(row, total))
+	}
+	totals := []interface{}{"合计"}
+	sum := 0
+	for _, author := range authors {
+		count := 0
+		for _, reviewer := range reviewers {
+			count += r.Reviews.Matrix[[2]string{reviewer, author}]
+		}
+		sum += count
+		totals = append(totals, count)
+	}
+	matrix.Rows = append(matrix.Rows, append(totals, sum))
+
+	return []Table{users, matrix}
+}
+
+// directPushTables 生成直接推送的用户、项目汇总表和明细表
+func (r *Report) directPushTables() []Table {
+	type pushStats struct {
+		pushes, additions, deletions int
+	}
+	add := func(m map[string]pushStats, key string, push gitlab.DirectPush) {
+		s := m[key]
+		s.pushes++
+		s.additions += push.Stats.Additions
+		s.deletions += push.Stats.Deletions
+		m[key] = s
+	}
+	cells := func(s pushStats) []interface{} {
+		return []interface{}{s.pushes, s.additions, s.deletions, s.additions + s.deletions}
+	}
+
+	byUser := make(map[string]pushStats)
+	byUserProject := make(map[string]map[string]pushStats)
+	byProject := make(map[string]pushStats)
+	for _, push := range r.DirectPushes {
+		add(byUser, push.AuthorName, push)
+		if byUserProject[push.AuthorName] == nil {
+			byUserProject[push.AuthorName] = make(map[string]pushStats)
+		}
+		add(byUserProject[push.AuthorName], push.ProjectID, push)
+		add(byProject, push.ProjectID, push)
+	}
+
+	pushHeader := []string{"直接推送数", "增加行数", "删除行数", "总代码量"}
+	users := Table{
+		Name:   "direct_pushes_users",
+		Title:  "用户直接推送",
+		Header: append([]string{"用户名", "项目 ID", "项目名称", "项目路径"}, pushHeader...),
+	}
+	for _, user := range SortedKeys(byUser) {
+		users.Rows = append(users.Rows, append([]interface{}{user, "", "合计", ""}, cells(byUser[user])...))
+		for _, projectID := range SortedKeys(byUserProject[user]) {
+			project := r.Project(projectID)
+			row := []interface{}{user, projectID, project.Name, project.PathWithNamespace}
+			users.Rows = append(users.Rows, append(row, cells(byUserProject[user][projectID])...))
+		}
+	}
+
+	projects := Table{
+		Name:   "direct_pushes_projects",
+		Title:  "项目直接推送",
+		Header: append([]string{"项目 ID", "项目名称", "项目路径"}, pushHeader...),
+	}
+	for _, projectID := range SortedKeys(byProject) {
+		project := r.Project(projectID)
+		row := []interface{}{projectID, project.Name, project.PathWithNamespace}
+		projects.Rows = append(projects.Rows, append(row, cells(byProject[projectID])...))
+	}
+
+	details := Table{
+		Name:   "direct_pushes",
+		Title:  "直接推送明细",
+		Header: []string{"项目 ID", "项目路径", "分支", "提交 SHA", "作者", "邮箱", "提交时间", "提交信息", "增加行数", "删除行数"},
+	}
+	for _, push := range r.DirectPushes {
+		details.Rows = append(details.Rows, []interface{}{
+			push.ProjectID, r.Project(push.ProjectID).PathWithNamespace, push.Branch, push.ID, push.AuthorName,
+			push.AuthorEmail, formatTime(&push.CommittedDate), push.Subject(), push.Stats.Additions, push.Stats.Deletions,
+		})
+	}
+
+	return []Table{users, projects, details}
+}
+
+// pipelineTables 生成项目和触发用户的流水线统计表
+func (r *Report) pipelineTables() []Table {
+	pipelineHeader := []string{"流水线数", "成功数", "失败数", "取消数", "跳过数", "成功率(%)", "失败率(%)", "平均时长(分钟)", "中位时长(分钟)"}
+
+	projects := Table{
+		Name:   "pipelines_projects",
+		Title:  "项目流水线",
+		Header: append([]string{"项目 ID", "项目名称", "项目路径"}, pipelineHeader...),
+	}
+	for _, projectID := range SortedKeys(r.Pipelines.Projects) {
+		project := r.Project(projectID)
+		row := []interface{}{projectID, project.Name, project.PathWithNamespace}
+		projects.Rows = append(projects.Rows, append(row, pipelineCells(r.Pipelines.Projects[projectID])...))
+	}
+
+	users := Table{
+		Name:   "pipelines_users",
+		Title:  "用户流水线",
+		Header: append([]string{"触发用户", "项目 ID", "项目名称", "项目路径"}, pipelineHeader...),
+	}
+	for _, user := range SortedKeys(r.Pipelines.Users) {
+		users.Rows = append(users.Rows, append([]interface{}{user, "", "合计", ""}, pipelineCells(r.Pipelines.Users[user])...))
+		var projectIDs []string
+		for key := range r.Pipelines.UserProjects {
+			if key[0] == user {
+				projectIDs = append(projectIDs, key[1])
+			}
+		}
+		sort.Strings(projectIDs)
+		for _, projectID := range projectIDs {
+			project := r.Project(projectID)
+			row := []interface{}{user, projectID, project.Name, project.PathWithNamespace}
+			users.Rows = append(users.Rows, append(row, pipelineCells(r.Pipelines.UserProjects[[2]string{user, projectID}])...))
+		}
+	}
+
+	return []Table{projects, users}
+}
+
+// doraTables 生成 DORA 指标汇总表以及按天、按周的时间序列表
+func (r *Report) doraTables() []Table {
+	summary := Table{
+		Name:  "dora_summary",
+		Title: "DORA 指标 (" + r.DORA.Environment + ")",
+		Header: []string{"项目 ID", "项目名称", "项目路径", "天数", "部署数", "部署频率(次/天)", "前置时间平均(小时)", "前置时间中位数(小时)",
+			"失败部署数", "变更失败率(%)", "事故数", "已恢复事故数", "恢复时长平均(小时)", "恢复时长中位数(小时)"},
+	}
+	for _, projectID := range SortedKeys(r.DORA.Summary) {
+		s := r.DORA.Summary[projectID]
+		row := append(r.doraProjectCells(projectID), s.Days, s.Deployments, roundPercent(s.DeploymentFrequency()),
+			hours(gitlab.AverageDuration(s.LeadTimes)), hours(gitlab.MedianDuration(s.LeadTimes)),
+			s.FailedDeployments, roundPercent(s.ChangeFailureRate()), s.Incidents, len(s.RestoreTimes),
+			hours(gitlab.AverageDuration(s.RestoreTimes)), hours(gitlab.MedianDuration(s.RestoreTimes)))
+		summary.Rows = append(summary.Rows, row)
+	}
+
+	return []Table{
+		summary,
+		r.doraSeriesTable("dora_daily", "DORA 指标（按天）", r.DORA.Daily),
+		r.doraSeriesTable("dora_weekly", "DORA 指标（按周）", r.DORA.Weekly),
+	}
+}
+
+// doraSeriesTable 生成 DORA 指标时间序列表
+func (r *Report) doraSeriesTable(name, title string, points []gitlab.DORAPoint) Table {
+	table := Table{
+		Name:  name,
+		Title: title,
+		Header: []string{"开始日期", "项目 ID", "项目名称", "项目路径", "部署数", "失败部署数", "变更失败率(%)",
+			"前置时间中位数(小时)", "事故数", "恢复时长中位数(小时)"},
+	}
+	for _, p := range points {
+		row := append([]interface{}{p.Start.Format("2006-01-02")}, r.doraProjectCells(p.ProjectID)...)
+		row = append(row, p.Deployments, p.FailedDeployments, roundPercent(p.ChangeFailureRate()),
+			hours(gitlab.MedianDuration(p.LeadTimes)), p.Incidents, hours(gitlab.MedianDuration(p.RestoreTimes)))
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// doraProjectCells DORA 表中的项目列，项目 ID 为空表示全部项目
+func (r *Report) doraProjectCells(projectID string) []interface{} {
+	if projectID == "" {
+		return []interface{}{"", "全部", ""}
+	}
+	project := r.Project(projectID)
+	return []interface{}{projectID, project.Name, project.PathWithNamespace}
+}
+
+// issueTables 生成按负责人、项目、标签和里程碑汇总的议题统计表
+func (r *Report) issueTables() []Table {
+	issueHeader := []string{"创建数", "关闭数", "周期平均(天)", "周期中位数(天)", "预估时间(小时)", "累计已用时间(小时)", "已用/预估(%)"}
+
+	assignees := Table{
+		Name:   "issues_assignees",
+		Title:  "负责人议题",
+		Header: append([]string{"负责人", "项目 ID", "项目名称", "项目路径"}, issueHeader...),
+	}
+	for _, assignee := range SortedKeys(r.Issues.Assignees) {
+		assignees.Rows = append(assignees.Rows, append([]interface{}{assignee, "", "合计", ""}, issueCells(r.Issues.Assignees[assignee])...))
+		var projectIDs []string
+		for key := range r.Issues.AssigneeProjects {
+			if key[0] == assignee {
+				projectIDs = append(projectIDs, key[1])
+			}
+		}
+		sort.Strings(projectIDs)
+		for _, projectID := range projectIDs {
+			project := r.Project(projectID)
+			row := []interface{}{assignee, projectID, project.Name, project.PathWithNamespace}
+			assignees.Rows = append(assignees.Rows, append(row, issueCells(r.Issues.AssigneeProjects[[2]string{assignee, projectID}])...))
+		}
+	}
+
+	projects := Table{
+		Name:   "issues_projects",
+		Title:  "项目议题",
+		Header: append([]string{"项目 ID", "项目名称", "项目路径"}, issueHeader...),
+	}
+	for _, projectID := range SortedKeys(r.Issues.Projects) {
+		project := r.Project(projectID)
+		row := []interface{}{projectID, project.Name, project.PathWithNamespace}
+		projects.Rows = append(projects.Rows, append(row, issueCells(r.Issues.Projects[projectID])...))
+	}
+
+	labels := Table{
+		Name:   "issues_labels",
+		Title:  "标签议题",
+		Header: append([]string{"标签"}, issueHeader...),
+	}
+	for _, label := range SortedKeys(r.Issues.Labels) {
+		labels.Rows = append(labels.Rows, append([]interface{}{label}, issueCells(r.Issues.Labels[label])...))
+	}
+
+	milestones := Table{
+		Name:   "issues_milestones",
+		Title:  "里程碑议题",
+		Header: append([]string{"里程碑"}, issueHeader...),
+	}
+	for _, milestone := range SortedKeys(r.Issues.Milestones) {
+		milestones.Rows = append(milestones.Rows, append([]interface{}{milestone}, issueCells(r.Issues.Milestones[milestone])...))
+	}
+
+	return []Table{assignees, projects, labels, milestones}
+}
+
+// bucketTable 生成按时间区间的用户和项目代码量表，区间内的被回滚行数不单独统计
+func (r *Report) bucketTable() Table {
+	header := statsHeader()
+	table := Table{
+		Name:   "buckets",
+		Title:  "时间区间统计",
+		Header: append([]string{"区间", "区间开始日期", "用户名", "项目 ID", "项目名称", "项目路径"}, header[:len(header)-1]...),
+	}
+	for _, bucket := range r.Buckets {
+		prefix := []interface{}{bucket.Label, bucket.Start.Format("2006-01-02")}
+		for _, user := range SortedKeys(bucket.Stats) {
+			stat := bucket.Stats[user]
+			row := append(append([]interface{}{}, prefix...), user, "", "合计", "")
+			cells := userStatsCells(stat)
+			table.Rows = append(table.Rows, append(row, cells[:len(cells)-1]...))
+			for _, projectID := range SortedKeys(stat.Projects) {
+				project := r.Project(projectID)
+				row := append(append([]interface{}{}, prefix...), user, projectID, project.Name, project.PathWithNamespace)
+				cells := statsCells(stat.Projects[projectID])
+				table.Rows = append(table.Rows, append(row, cells[:len(cells)-1]...))
+			}
+		}
+	}
+	return table
+}
+
+// 获取数据阶段的中文描述
+var stageNames = map[string]string{
+	StageCommits:        "提交",
+	StageCompareCommits: "对比时间段提交",
+	StageMergeRequests:  "合并请求",
+	StageDirectPushes:   "直接推送",
+	StagePipelines:      "流水线",
+	StageDeployments:    "部署",
+	StageIssues:         "议题",
+}
+
+// failureTable 生成获取数据失败的项目表
+func (r *Report) failureTable() Table {
+	table := Table{
+		Name:   "failures",
+		Title:  "获取失败",
+		Header: []string{"项目 ID", "项目名称", "项目路径", "阶段", "错误信息"},
+	}
+	for _, failure := range r.Failures {
+		project := r.Project(failure.ProjectID)
+		table.Rows = append(table.Rows, []interface{}{failure.ProjectID, project.Name, project.PathWithNamespace, stageNames[failure.Stage], failure.Error})
+	}
+	return table
+}
+
+// issueCells 议题统计列的单元格
+func issueCells(s gitlab.IssueStats) []interface{} {
+	return []interface{}{
+		s.Opened, s.Closed,
+		roundPercent(gitlab.AverageDuration(s.CycleTimes).Hours() / 24), roundPercent(gitlab.MedianDuration(s.CycleTimes).Hours() / 24),
+		roundPercent(float64(s.TimeEstimate) / 3600), roundPercent(float64(s.TimeSpent) / 3600), share(s.TimeSpent, s.TimeEstimate),
+	}
+}
+
+// pipelineCells 流水线统计列的单元格
+func pipelineCells(s gitlab.PipelineStats) []interface{} {
+	return []interface{}{
+		s.Total, s.Success, s.Failed, s.Canceled, s.Skipped,
+		roundPercent(s.SuccessRate()), roundPercent(s.FailureRate()),
+		roundPercent(gitlab.AverageDuration(s.Durations).Minutes()), roundPercent(gitlab.MedianDuration(s.Durations).Minutes()),
+	}
+}
+
+// mergeRequestCells 合并请求统计列的单元格
+func mergeRequestCells(s gitlab.MergeRequestStats) []interface{} {
+	lines := s.Additions + s.Deletions
+	return []interface{}{
+		s.Opened, s.Merged, s.Closed, len(s.TimeToFirstReview),
+		hours(gitlab.AverageDuration(s.TimeToFirstReview)), hours(gitlab.MedianDuration(s.TimeToFirstReview)),
+		hours(gitlab.AverageDuration(s.TimeToMerge)), hours(gitlab.MedianDuration(s.TimeToMerge)),
+		lines, ratio(lines, s.Merged), ratio(s.Revisions, s.Merged),
+	}
+}
+
+// hours 将时长转换为保留两位小数的小时数
+func hours(d time.Duration) float64 {
+	return roundPercent(d.Hours())
+}
+
+// ratio 返回保留两位小数的平均值，除数为 0 时返回 0
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundPercent(float64(part) / float64(total))
+}
+
+// formatTime 格式化可能为空的时间
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// SortedKeys 返回排序后的 map 键
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// roundPercent 将百分比保留两位小数
+func roundPercent(v float64) float64 {
+	return math.Round(v*100) / 100
+}
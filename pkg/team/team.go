@@ -0,0 +1,359 @@
+package team
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doufum/gitlab-analyze/pkg/gitlab"
+	"github.com/xuri/excelize/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Membership 用户在某段时间内所属的团队和部门
+type Membership struct {
+	User       string `yaml:"user"`
+	Email      string `yaml:"email"`
+	Team       string `yaml:"team"`
+	Department string `yaml:"department"`
+	// 生效和失效日期（YYYY-MM-DD，均包含在内），为空表示不限
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+
+	from time.Time
+	to   time.Time
+}
+
+// Mapping 团队成员映射
+type Mapping struct {
+	Memberships []Membership
+}
+
+// GroupStats 团队或部门的汇总统计
+type GroupStats struct {
+	Name       string
+	Department string
+	Members    []string
+	gitlab.UserStats
+}
+
+// Rollup 团队和部门级别的汇总结果
+type Rollup struct {
+	Teams       []GroupStats
+	Departments []GroupStats
+	// 未在映射文件中找到团队的作者
+	Unmapped map[string]gitlab.UserStats
+}
+
+// mappingFile YAML 映射文件的结构
+type mappingFile struct {
+	Members []Membership `yaml:"members"`
+}
+
+// Load 从 YAML 或 Excel 文件加载团队映射
+//
+// YAML 文件格式：
+//
+//	members:
+//	  - user: 张三
+//	    email: zhangsan@example.com
+//	    team: 支付组
+//	    department: 研发部
+//	    from: 2024-01-01
+//	    to: 2024-06-30
+//
+// Excel 文件读取名为 teams 的工作表（不存在时读取第一个工作表），
+// 列依次为：用户、团队、部门、生效日期、失效日期、邮箱，第一行为表头。
+func Load(filePath string) (*Mapping, error) {
+	var memberships []Membership
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		memberships, err = loadYAML(filePath)
+	case ".xlsx":
+		memberships, err = loadExcel(filePath)
+	default:
+		return nil, fmt.Errorf("不支持的团队映射文件格式: %s", filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析生效日期
+	for i := range memberships {
+		m := &memberships[i]
+		m.User = strings.TrimSpace(m.User)
+		m.Email = strings.TrimSpace(m.Email)
+		if m.User == "" && m.Email == "" {
+			return nil, fmt.Errorf("第 %d 条团队映射缺少用户", i+1)
+		}
+		if m.Team == "" {
+			return nil, fmt.Errorf("用户 %s 的团队映射缺少团队名称", m.User)
+		}
+		if m.From != "" {
+			if m.from, err = time.ParseInLocation("2006-01-02", m.From, time.Local); err != nil {
+				return nil, fmt.Errorf("用户 %s 的生效日期格式无效: %s", m.User, m.From)
+			}
+		}
+		if m.To != "" {
+			if m.to, err = time.ParseInLocation("2006-01-02", m.To, time.Local); err != nil {
+				return nil, fmt.Errorf("用户 %s 的失效日期格式无效: %s", m.User, m.To)
+			}
+			// 失效日期当天仍然有效
+			m.to = m.to.AddDate(0, 0, 1)
+		}
+	}
+
+	return &Mapping{Memberships: memberships}, nil
+}
+
+// loadYAML 读取 YAML 格式的团队映射
+func loadYAML(filePath string) ([]Membership, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取团队映射文件失败: %v", err)
+	}
+
+	var file mappingFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析团队映射文件失败: %v", err)
+	}
+	return file.Members, nil
+}
+
+// loadExcel 读取 Excel 格式的团队映射
+func loadExcel(filePath string) ([]Membership, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开 Excel 文件失败: %v", err)
+	}
+	defer f.Close()
+
+	sheetName := f.GetSheetName(0)
+	if index, err := f.GetSheetIndex("teams"); err == nil && index >= 0 {
+		sheetName = "teams"
+	}
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("读取工作表失败: %v", err)
+	}
+
+	var memberships []Membership
+	for i, row := range rows {
+		// 跳过表头和不完整的行
+		if i == 0 || len(row) < 2 {
+			continue
+		}
+		m := Membership{User: row[0], Team: row[1]}
+		if len(row) > 2 {
+			m.Department = row[2]
+		}
+		if len(row) > 3 {
+			m.From = strings.TrimSpace(row[3])
+		}
+		if len(row) > 4 {
+			m.To = strings.TrimSpace(row[4])
+		}
+		if len(row) > 5 {
+			m.Email = row[5]
+		}
+		memberships = append(memberships, m)
+	}
+	return memberships, nil
+}
+
+// activeAt 判断映射在指定时间是否有效
+func (m Membership) activeAt(t time.Time) bool {
+	if !m.from.IsZero() && t.Before(m.from) {
+		return false
+	}
+	if !m.to.IsZero() && !t.Before(m.to) {
+		return false
+	}
+	return true
+}
+
+// overlaps 判断映射是否与时间范围有交集，end 不包含在内
+func (m Membership) overlaps(start, end time.Time) bool {
+	if !m.from.IsZero() && !m.from.Before(end) {
+		return false
+	}
+	if !m.to.IsZero() && !m.to.After(start) {
+		return false
+	}
+	return true
+}
+
+// matches 判断映射是否属于指定作者，用户名或邮箱匹配即可
+func (m Membership) matches(user, email string) bool {
+	if m.User != "" && strings.EqualFold(m.User, user) {
+		return true
+	}
+	return m.Email != "" && email != "" && strings.EqualFold(m.Email, email)
+}
+
+// Lookup 查找作者在指定时间所属的团队
+func (mp *Mapping) Lookup(user, email string, at time.Time) (Membership, bool) {
+	for _, m := range mp.Memberships {
+		if m.matches(user, email) && m.activeAt(at) {
+			return m, true
+		}
+	}
+	return Membership{}, false
+}
+
+// ExpandUsers 将目标用户列表中的团队或部门名称展开为其成员，
+// 成员在 [start, end) 内任意时间属于该团队即会被选中，其余条目原样保留。
+// 与 Lookup 相同，成员按用户名（不区分大小写）或邮箱匹配 commits 中的作者，展开结果包含提交中实际的作者名，
+// 因此只配置了邮箱的成员也能被选中
+func (mp *Mapping) ExpandUsers(targets []string, start, end time.Time, commits []gitlab.Commit) []string {
+	var users []string
+	seen := make(map[string]bool)
+	add := func(user string) {
+		if !seen[user] {
+			seen[user] = true
+			users = append(users, user)
+		}
+	}
+
+	// 提交中出现过的作者名和邮箱
+	type author struct{ name, email string }
+	var authors []author
+	seenAuthors := make(map[author]bool)
+	for _, commit := range commits {
+		a := author{commit.AuthorName, commit.AuthorEmail}
+		if !seenAuthors[a] {
+			seenAuthors[a] = true
+			authors = append(authors, a)
+		}
+	}
+
+	for _, target := range targets {
+		expanded := false
+		for _, m := range mp.Memberships {
+			if (m.Team != target && m.Department != target) || !m.overlaps(start, end) {
+				continue
+			}
+			if m.User != "" {
+				add(m.User)
+				expanded = true
+			}
+			for _, a := range authors {
+				if m.matches(a.name, a.email) {
+					add(a.name)
+					expanded = true
+				}
+			}
+		}
+		if !expanded {
+			add(target)
+		}
+	}
+	return users
+}
+
+// Rollup 按提交时间所属的团队汇总代码量，被回滚的代码量按原提交时间计入当时所在的团队
+func (mp *Mapping) Rollup(commits []gitlab.Commit, reverts []gitlab.RevertPair) *Rollup {
+	teams := make(map[string]*GroupStats)
+	departments := make(map[string]*GroupStats)
+	var unmapped []gitlab.Commit
+
+	for _, commit := range commits {
+		m, ok := mp.Lookup(commit.AuthorName, commit.AuthorEmail, commit.CommittedDate)
+		if !ok {
+			unmapped = append(unmapped, commit)
+			continue
+		}
+
+		team := group(teams, m.Team)
+		team.Department = m.Department
+		addCommit(team, commit)
+
+		if m.Department != "" {
+			addCommit(group(departments, m.Department), commit)
+		}
+	}
+
+	for _, pair := range reverts {
+		if pair.Original == nil {
+			continue
+		}
+		original := *pair.Original
+		m, ok := mp.Lookup(original.AuthorName, original.AuthorEmail, original.CommittedDate)
+		if !ok {
+			continue
+		}
+		addReverted(group(teams, m.Team), original)
+		if m.Department != "" {
+			addReverted(group(departments, m.Department), original)
+		}
+	}
+
+	return &Rollup{
+		Teams:       sortedGroups(teams),
+		Departments: sortedGroups(departments),
+		Unmapped:    gitlab.CommitsToUserStats(unmapped),
+	}
+}
+
+// group 获取或创建团队、部门的汇总
+func group(groups map[string]*GroupStats, name string) *GroupStats {
+	g, exists := groups[name]
+	if !exists {
+		g = &GroupStats{Name: name, UserStats: gitlab.UserStats{Projects: make(map[string]gitlab.ProjectStats)}}
+		groups[name] = g
+	}
+	return g
+}
+
+// addCommit 将提交计入汇总
+func addCommit(g *GroupStats, commit gitlab.Commit) {
+	g.Commits++
+	g.Additions += commit.Stats.Additions
+	g.Deletions += commit.Stats.Deletions
+	g.Changes += commit.Stats.Total
+	g.Total += commit.Stats.Additions + commit.Stats.Deletions
+
+	projectStats := g.Projects[commit.ProjectID]
+	projectStats.Commits++
+	projectStats.Additions += commit.Stats.Additions
+	projectStats.Deletions += commit.Stats.Deletions
+	projectStats.Changes += commit.Stats.Total
+	g.Projects[commit.ProjectID] = projectStats
+
+	for _, member := range g.Members {
+		if member == commit.AuthorName {
+			return
+		}
+	}
+	g.Members = append(g.Members, commit.AuthorName)
+}
+
+// addReverted 将被回滚的原提交计入汇总
+func addReverted(g *GroupStats, original gitlab.Commit) {
+	lines := original.Stats.Additions + original.Stats.Deletions
+	g.Reverted += lines
+	projectStats := g.Projects[original.ProjectID]
+	projectStats.Reverted += lines
+	g.Projects[original.ProjectID] = projectStats
+}
+
+// sortedGroups 按总代码量降序返回汇总结果
+func sortedGroups(groups map[string]*GroupStats) []GroupStats {
+	result := make([]GroupStats, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.Members)
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
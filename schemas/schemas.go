@@ -0,0 +1,32 @@
+// Package schemas 内嵌 JSON 和 NDJSON 输出的 JSON Schema 文件，随二进制文件一起发布
+package schemas
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed *.schema.json
+var files embed.FS
+
+// Names 返回内嵌的 Schema 名称，如 report.v1、record.v1，按字母排序
+func Names() []string {
+	entries, _ := files.ReadDir(".")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".schema.json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get 返回指定名称的 Schema 内容
+func Get(name string) ([]byte, error) {
+	data, err := files.ReadFile(name + ".schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("不存在的 Schema: %s，可选值为 %s", name, strings.Join(Names(), "、"))
+	}
+	return data, nil
+}